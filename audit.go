@@ -0,0 +1,331 @@
+//
+// FilePath    : zap-smap\audit.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : audit-binary 子命令, 校验构建产物中是否包含当前源码的注入值
+//
+
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+// auditValuePattern 二进制中 file:line 与 file:line | func 形式的候选注入值。
+// Go 的字符串常量之间没有分隔符, 匹配结果的前后可能粘连相邻的字符串, 由 auditStale 按源码文件裁剪
+var auditValuePattern = regexp.MustCompile(`[A-Za-z0-9_./-]+\.go:[0-9]+(?: \| [A-Za-z0-9_./()*\[\]-]+)?`)
+
+// auditResult audit-binary 的对照结果
+type auditResult struct {
+	total   int      // 源码中的注入值个数
+	missing []string // 源码中存在但二进制中不存在的注入值
+	stale   []string // 二进制中存在但与当前源码不一致的注入值
+}
+
+// runAudit 读取 ELF/Mach-O/PE 二进制的数据段, 与仓库源码中由 verifyFile 得到的注入值对照,
+// 报告二进制中缺失的位置与已过期的注入值, 存在问题时返回错误
+func runAudit(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("audit-binary", flag.ContinueOnError)
+	bindFormatFlags(fs)
+	bindSkipFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: zap-smap audit-binary [flags] <binary>")
+	}
+
+	if err := initFormatModes(); err != nil {
+		return err
+	}
+
+	baseDir := *pathFlag
+	if err := initSkipRules(baseDir); err != nil {
+		return err
+	}
+
+	data, err := readBinaryData(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	values, files, err := collectInjectedValues(baseDir)
+	if err != nil {
+		return err
+	}
+
+	var res auditResult
+	if *tokenFlg {
+		sm, err := loadSourceMap(smapPath(baseDir))
+		if err != nil {
+			return err
+		}
+
+		res = auditTokens(data, values, sm.Entries)
+	} else {
+		res = auditLocations(data, values, files)
+	}
+
+	printAuditResult(out, fs.Arg(0), res)
+
+	if len(res.missing) > 0 || len(res.stale) > 0 {
+		return fmt.Errorf("%d sites missing from binary, %d stale values", len(res.missing), len(res.stale))
+	}
+
+	return nil
+}
+
+// readBinaryData 读取 ELF、Mach-O 或 PE 文件中已初始化且不可执行的数据段
+func readBinaryData(path string) ([][]byte, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+
+		var data [][]byte
+
+		for _, s := range f.Sections {
+			if s.Type != elf.SHT_PROGBITS || s.Flags&elf.SHF_ALLOC == 0 || s.Flags&elf.SHF_EXECINSTR != 0 {
+				continue
+			}
+
+			b, err := s.Data()
+			if err != nil {
+				return nil, fmt.Errorf("%s: read section %s: %w", path, s.Name, err)
+			}
+
+			data = append(data, b)
+		}
+
+		return data, nil
+	}
+
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+
+		var data [][]byte
+
+		for _, s := range f.Sections {
+			// 跳过代码段、调试信息与零填充段(S_ZEROFILL)
+			if (s.Seg == "__TEXT" && s.Name == "__text") || s.Seg == "__DWARF" || s.Flags&0xff == 0x1 {
+				continue
+			}
+
+			b, err := s.Data()
+			if err != nil {
+				return nil, fmt.Errorf("%s: read section %s: %w", path, s.Name, err)
+			}
+
+			data = append(data, b)
+		}
+
+		return data, nil
+	}
+
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+
+		var data [][]byte
+
+		for _, s := range f.Sections {
+			if s.Characteristics&pe.IMAGE_SCN_CNT_INITIALIZED_DATA == 0 || s.Characteristics&pe.IMAGE_SCN_MEM_EXECUTE != 0 {
+				continue
+			}
+
+			b, err := s.Data()
+			if err != nil {
+				return nil, fmt.Errorf("%s: read section %s: %w", path, s.Name, err)
+			}
+
+			data = append(data, b)
+		}
+
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("%s: not an ELF, Mach-O or PE binary", path)
+}
+
+// collectInjectedValues 遍历仓库, 以 verifyFile 收集每个目标日志调用期望的注入值, 同时返回仓库中全部 Go 文件的相对路径
+func collectInjectedValues(baseDir string) ([]string, map[string]bool, error) {
+	modulePath := readModulePath(baseDir)
+	fSet := token.NewFileSet()
+
+	var vr verifyResult
+
+	files := make(map[string]bool)
+
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return handleVerifyDir(path)
+		}
+
+		if shouldSkipFile(path) {
+			return nil
+		}
+
+		files[siteRel(path, baseDir)] = true
+
+		r, err := verifyFile(path, fSet, modulePath, baseDir)
+		if err != nil {
+			return handleFileError(err)
+		}
+
+		vr.add(r)
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vr.values, files, nil
+}
+
+// auditLocations 对照 file:line 形式的注入值: 源码中的值逐个在数据段中查找,
+// 数据段中指向仓库内文件但与源码不一致的值视为过期
+func auditLocations(data [][]byte, values []string, files map[string]bool) auditResult {
+	var res auditResult
+
+	res.missing, res.total = missingValues(data, values)
+
+	byFile := make(map[string][]string)
+
+	for _, v := range values {
+		if loc, ok := smaprt.ParseSite(v); ok {
+			byFile[loc.File] = append(byFile[loc.File], v)
+		}
+	}
+
+	seen := make(map[string]bool)
+
+	for _, b := range data {
+		for _, m := range auditValuePattern.FindAll(b, -1) {
+			v, ok := auditStale(string(m), files, byFile)
+			if ok && !seen[v] {
+				seen[v] = true
+				res.stale = append(res.stale, v)
+			}
+		}
+	}
+
+	sort.Strings(res.stale)
+
+	return res
+}
+
+// auditStale 将候选值裁剪到仓库内最长匹配的文件路径, 与该文件的期望值都不相符时返回裁剪后的值与 true。
+// 文件不在仓库内(例如依赖库)的候选值忽略
+func auditStale(m string, files map[string]bool, byFile map[string][]string) (string, bool) {
+	i := strings.Index(m, ".go:") + len(".go")
+
+	start := -1
+
+	for j := range i {
+		if files[m[j:i]] {
+			start = j
+			break
+		}
+	}
+
+	if start < 0 {
+		return "", false
+	}
+
+	v := m[start:]
+
+	// 候选值可能粘连其后的字符串, 以期望值为前缀即视为一致
+	for _, want := range byFile[v[:i-start]] {
+		if strings.HasPrefix(v, want) {
+			return "", false
+		}
+	}
+
+	return v, true
+}
+
+// auditTokens 对照 -token 模式的注入值: 映射文件中不属于当前源码的令牌出现在数据段中时视为过期
+func auditTokens(data [][]byte, values []string, entries map[string]smaprt.Site) auditResult {
+	var res auditResult
+
+	res.missing, res.total = missingValues(data, values)
+
+	current := make(map[string]bool, len(values))
+	for _, v := range values {
+		current[v] = true
+	}
+
+	for tok, loc := range entries {
+		if !current[tok] && containsValue(data, tok) {
+			res.stale = append(res.stale, fmt.Sprintf("%s (%s)", tok, loc))
+		}
+	}
+
+	sort.Strings(res.stale)
+
+	return res
+}
+
+// missingValues 返回数据段中不存在的注入值(按源码顺序去重)与去重后的注入值个数
+func missingValues(data [][]byte, values []string) ([]string, int) {
+	var missing []string
+
+	seen := make(map[string]bool)
+
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+
+		seen[v] = true
+
+		if !containsValue(data, v) {
+			missing = append(missing, v)
+		}
+	}
+
+	return missing, len(seen)
+}
+
+// containsValue 判断任一数据段中是否包含 v
+func containsValue(data [][]byte, v string) bool {
+	for _, b := range data {
+		if bytes.Contains(b, []byte(v)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printAuditResult 输出对照结果
+func printAuditResult(out io.Writer, bin string, res auditResult) {
+	fmt.Fprintf(out, "[AUDIT] %s: %d of %d sites present\n", bin, res.total-len(res.missing), res.total)
+
+	for _, v := range res.missing {
+		fmt.Fprintf(out, "[AUDIT] missing: %s\n", v)
+	}
+
+	for _, v := range res.stale {
+		fmt.Fprintf(out, "[AUDIT] stale: %s\n", v)
+	}
+}
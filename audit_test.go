@@ -0,0 +1,113 @@
+//
+// FilePath    : zap-smap\audit_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : audit-binary 子命令单测
+//
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+// auditSample audit-binary 单测使用的源码, 日志调用位于第 6、7 行
+const auditSample = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("started", zap.String("fl", "svc/a.go:6"))
+	zap.L().Warn("slow", zap.String("fl", "svc/a.go:7"))
+}
+`
+
+// TestAuditLocations 测试缺失与过期值的识别, 包括与相邻字符串粘连的候选值
+func TestAuditLocations(t *testing.T) {
+	data := [][]byte{
+		[]byte("startedsvc/a.go:6slowsvc/a.go:9other/dep.go:3"),
+		[]byte("xsvc/a.go:61"),
+	}
+	values := []string{"svc/a.go:6", "svc/a.go:7", "svc/a.go:6"}
+	files := map[string]bool{"svc/a.go": true, "a.go": true}
+
+	res := auditLocations(data, values, files)
+
+	if res.total != 2 || strings.Join(res.missing, ",") != "svc/a.go:7" {
+		t.Fatalf("unexpected missing: total=%d %v", res.total, res.missing)
+	}
+
+	if strings.Join(res.stale, ",") != "svc/a.go:9" {
+		t.Fatalf("unexpected stale: %v", res.stale)
+	}
+}
+
+// TestAuditTokens 测试 -token 模式下映射文件中已不属于源码的令牌被识别为过期
+func TestAuditTokens(t *testing.T) {
+	data := [][]byte{[]byte("aaaaaaaaaaaaaaaa..cccccccccccccccc")}
+	entries := map[string]smaprt.Site{
+		"aaaaaaaaaaaaaaaa": {File: "svc/a.go", Line: 6},
+		"bbbbbbbbbbbbbbbb": {File: "svc/a.go", Line: 7},
+		"cccccccccccccccc": {File: "svc/a.go", Line: 3},
+	}
+
+	res := auditTokens(data, []string{"aaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbb"}, entries)
+
+	if strings.Join(res.missing, ",") != "bbbbbbbbbbbbbbbb" || strings.Join(res.stale, ",") != "cccccccccccccccc (svc/a.go:3)" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+// TestRunAudit_Binary 构建包含注入值的二进制, 测试数据段的读取与报告输出
+func TestRunAudit_Binary(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	resetGlobals()
+	defer resetGlobals()
+
+	repo := writeTree(t, map[string]string{"go.mod": "module example.com/app\n", "svc/a.go": auditSample})
+
+	// 二进制只包含 svc/a.go:6 与已过期的 svc/a.go:9
+	prog := t.TempDir()
+	writeFile(t, prog, "go.mod", "module example.com/bin\n\ngo 1.21\n")
+	writeFile(t, prog, "main.go", "package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Stdout.WriteString(\"svc/a.go:6\" + os.Args[0] + \"svc/a.go:9\")\n}\n")
+
+	bin := filepath.Join(prog, "app")
+
+	cmd := exec.Command(goBin, "build", "-o", bin, ".")
+	cmd.Dir = prog
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=", "CGO_ENABLED=0")
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+
+	var out bytes.Buffer
+
+	err = runAudit([]string{"-path", repo, bin}, &out)
+	if err == nil || err.Error() != "1 sites missing from binary, 1 stale values" {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+
+	s := out.String()
+	for _, w := range []string{"1 of 2 sites present", "[AUDIT] missing: svc/a.go:7", "[AUDIT] stale: svc/a.go:9"} {
+		if !strings.Contains(s, w) {
+			t.Fatalf("expected %q, got:\n%s", w, s)
+		}
+	}
+
+	if err := runAudit([]string{"-path", repo, filepath.Join(repo, "go.mod")}, &out); err == nil || !strings.Contains(err.Error(), "not an ELF, Mach-O or PE binary") {
+		t.Fatalf("expected unsupported format error, got %v", err)
+	}
+}
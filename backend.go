@@ -0,0 +1,242 @@
+//
+// FilePath    : zap-smap\backend.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 日志库 backend 的匹配与字段参数的通用解析
+//
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// importedBackends 返回 file 导入的日志库对应的 backend, 未导入任何日志库时返回 nil
+func importedBackends(file *ast.File) []*backend {
+	var bs []*backend
+
+	for _, b := range activeBackends {
+		for _, imp := range file.Imports {
+			if strings.Trim(imp.Path.Value, "\"") == b.importPath {
+				bs = append(bs, b)
+				break
+			}
+		}
+	}
+
+	return bs
+}
+
+// isChainStyle 判断 backend 的字段是否以链式方法调用表示, 例如 zerolog 的 .Str(k, v)
+func (b *backend) isChainStyle() bool {
+	return b.chainEnds != nil
+}
+
+// fieldCall 判断 expr 是否为 <ident>.<Func>(...) 形式的字段构造调用, 返回调用与函数名。
+// 链式 backend 中字段为调用链上的方法调用, 接收者可以是任意表达式
+func (b *backend) fieldCall(expr ast.Expr) (*ast.CallExpr, string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, "", false
+	}
+
+	funSel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+
+	if b.isChainStyle() {
+		return call, funSel.Sel.Name, true
+	}
+
+	id, ok := funSel.X.(*ast.Ident)
+	if !ok || id.Name != b.fieldIdent {
+		return nil, "", false
+	}
+
+	return call, funSel.Sel.Name, true
+}
+
+// matchesFieldKey 判断 expr 是否为 <ident>.<Something>(key, ...) 调用且第一个参数为字符串字面量等于 key
+func (b *backend) matchesFieldKey(expr ast.Expr, key string) bool {
+	call, _, ok := b.fieldCall(expr)
+	if !ok || len(call.Args) == 0 {
+		return false
+	}
+
+	return parseLitKey(call.Args[0]) == key
+}
+
+// isFieldFunc 判断 call 是否为注入字段使用的构造函数, 例如 zap.String
+func (b *backend) isFieldFunc(call *ast.CallExpr) bool {
+	_, name, ok := b.fieldCall(call)
+
+	return ok && name == b.fieldFunc
+}
+
+// fieldFuncName 返回注入字段构造函数的显示名称, 例如 zap.String、.Str
+func (b *backend) fieldFuncName() string {
+	if b.isChainStyle() {
+		return "." + b.fieldFunc
+	}
+
+	return b.fieldIdent + "." + b.fieldFunc
+}
+
+// makeFieldArg 构造 <ident>.String(field, v) 表达式。
+// 链式 backend 构造 .Str(field, v) 方法调用, 其接收者由 logCall.setFields 串联
+func (b *backend) makeFieldArg(v string) ast.Expr {
+	var recv ast.Expr
+	if !b.isChainStyle() {
+		recv = ast.NewIdent(b.fieldIdent)
+	}
+
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{X: recv, Sel: ast.NewIdent(b.fieldFunc)},
+		Args: []ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(*fieldFlg)},
+			makeValueExpr(v, token.NoPos),
+		},
+	}
+}
+
+// isInjectedField 判断字段表达式是否具有工具注入的形态: <ident>.String(key, "<注入位置>") 或引用位置常量
+func (b *backend) isInjectedField(e ast.Expr) bool {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 || !b.isFieldFunc(call) {
+		return false
+	}
+
+	return isInjectedValue(call.Args[1])
+}
+
+// levelFromExpr 从 zap.DebugLevel/slog.LevelDebug 形式的级别参数中解析级别名称,
+// 无法静态确定时返回 Log, 对应 -level-policy 中的通用 Log 策略
+func (b *backend) levelFromExpr(e ast.Expr) string {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return zapMethodLog
+	}
+
+	id, ok := sel.X.(*ast.Ident)
+	if !ok || !b.levelPkgs[id.Name] {
+		return zapMethodLog
+	}
+
+	if l, ok := b.levelConsts[sel.Sel.Name]; ok {
+		return l
+	}
+
+	return zapMethodLog
+}
+
+// fieldRef 描述日志调用中的一个字段, 可以是字段构造调用, 也可以是 "key", value 键值对
+type fieldRef struct {
+	index int           // 字段在 logCall.fields() 中的起始索引
+	width int           // 字段占用的参数个数, 键值对为 2
+	key   string        // 字段键, 无法静态确定时为空
+	call  *ast.CallExpr // 字段构造调用, 键值对形式为 nil
+	value ast.Expr      // 字段值表达式, 缺失时为 nil
+}
+
+// isPair 判断字段是否为 "key", value 键值对形式
+func (f fieldRef) isPair() bool {
+	return f.call == nil && f.width == 2
+}
+
+// parseFields 将 lc.fields() 解析为字段列表。
+// 支持键值对的 backend 中, 字符串字面量参数与其后的参数组成一个键值对(与 slog 的解析规则一致)
+func parseFields(lc logCall) []fieldRef {
+	exprs := lc.fields()
+
+	var refs []fieldRef
+
+	for i := 0; i < len(exprs); {
+		a := exprs[i]
+
+		if call, _, ok := lc.b.fieldCall(a); ok {
+			ref := fieldRef{index: i, width: 1, call: call}
+
+			if len(call.Args) > 0 {
+				ref.key = parseLitKey(call.Args[0])
+			}
+
+			if len(call.Args) > 1 {
+				ref.value = call.Args[1]
+			}
+
+			refs = append(refs, ref)
+			i++
+
+			continue
+		}
+
+		if lc.kvPairs && isStringLit(a) && i+1 < len(exprs) {
+			refs = append(refs, fieldRef{index: i, width: 2, key: parseLitKey(a), value: exprs[i+1]})
+			i += 2
+
+			continue
+		}
+
+		refs = append(refs, fieldRef{index: i, width: 1})
+		i++
+	}
+
+	return refs
+}
+
+// findField 在字段列表中查找键为 key 的字段
+func findField(lc logCall, key string) (fieldRef, bool) {
+	for _, f := range parseFields(lc) {
+		if f.key == key && (f.call != nil || f.isPair()) {
+			return f, true
+		}
+	}
+
+	return fieldRef{}, false
+}
+
+// isOwnedField 判断字段是否具有工具注入的形态: 构造调用形式要求为 <ident>.String(key, "<注入位置>"), 键值对形式要求值为注入位置
+func isOwnedField(lc logCall, f fieldRef) bool {
+	if f.isPair() {
+		return isInjectedValue(f.value)
+	}
+
+	return lc.b.isInjectedField(f.call)
+}
+
+// isInjectedValue 判断字段值是否具有工具注入的形态: 注入位置形态的字符串字面量(见 looksInjected)或 -locs 模式的位置常量引用
+func isInjectedValue(e ast.Expr) bool {
+	if isLocIdent(e) {
+		return true
+	}
+
+	bl, ok := e.(*ast.BasicLit)
+	if !ok || bl.Kind != token.STRING {
+		return false
+	}
+
+	v, err := strconv.Unquote(bl.Value)
+
+	return err == nil && looksInjected(v)
+}
+
+// makeValueExpr 构造注入字段的值表达式: -locs 模式下为位置常量引用, 否则为字符串字面量
+func makeValueExpr(v string, pos token.Pos) ast.Expr {
+	if locTables != nil {
+		return &ast.Ident{Name: v, NamePos: pos}
+	}
+
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(v), ValuePos: pos}
+}
+
+// isStringLit 判断表达式是否为字符串字面量
+func isStringLit(e ast.Expr) bool {
+	bl, ok := e.(*ast.BasicLit)
+
+	return ok && bl.Kind == token.STRING
+}
@@ -0,0 +1,135 @@
+//
+// FilePath    : zap-smap\buildgraph.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -for 模式, 按 main 包的构建依赖图限定处理的文件
+//
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// listedPackage go list -json 输出中用到的字段
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	CgoFiles   []string
+	Module     *struct {
+		Path string
+		Main bool
+	}
+	Error *struct {
+		Err string
+	}
+}
+
+// forFiles -for 模式下构建依赖图中属于主模块的源文件(绝对路径), nil 表示未启用
+var forFiles map[string]bool
+
+// initBuildGraph 解析 -for 参数: 在 baseDir 下以 go list -deps -json 离线解析所选 main 包的传递依赖,
+// 记录其中属于主模块的包参与构建的源文件; -tags 与环境变量 GOOS/GOARCH 决定参与构建的文件
+func initBuildGraph(baseDir string) error {
+	forFiles = nil
+
+	var patterns []string
+
+	for p := range strings.SplitSeq(*forFlg, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	args := []string{"list", "-deps", "-json"}
+	if *tagsFlg != "" {
+		args = append(args, "-tags", *tagsFlg)
+	}
+
+	args = append(args, "--")
+	args = append(args, patterns...)
+
+	var stdout, stderr bytes.Buffer
+
+	// GOPROXY=off 保证只使用模块缓存或 vendor 目录, 不访问网络
+	cmd := exec.Command("go", args...)
+	cmd.Dir = baseDir
+	cmd.Env = append(os.Environ(), "GOPROXY=off")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go list -deps %s: %v: %s", strings.Join(patterns, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	files, err := mainModuleFiles(&stdout)
+	if err != nil {
+		return err
+	}
+
+	forFiles = files
+
+	return nil
+}
+
+// mainModuleFiles 解码 go list -json 输出的包序列, 返回属于主模块的包中 GoFiles 与 CgoFiles 的绝对路径
+func mainModuleFiles(r io.Reader) (map[string]bool, error) {
+	files := make(map[string]bool)
+	dec := json.NewDecoder(r)
+
+	for {
+		var pkg listedPackage
+
+		err := dec.Decode(&pkg)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("decode go list output: %w", err)
+		}
+
+		if pkg.Error != nil {
+			return nil, fmt.Errorf("package %s: %s", pkg.ImportPath, pkg.Error.Err)
+		}
+
+		// 标准库与第三方依赖不处理
+		if pkg.Module == nil || !pkg.Module.Main {
+			continue
+		}
+
+		for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+			files[filepath.Join(pkg.Dir, name)] = true
+		}
+	}
+
+	return files, nil
+}
+
+// outsideBuildGraph 判断文件是否不在 -for 指定的构建依赖图中, 未启用 -for 时返回 false
+func outsideBuildGraph(path string) bool {
+	if forFiles == nil {
+		return false
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return true
+	}
+
+	return !forFiles[abs]
+}
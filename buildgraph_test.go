@@ -0,0 +1,94 @@
+//
+// FilePath    : zap-smap\buildgraph_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -for 单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildGraphFiles 包含 cmd/api 与 cmd/tool 两个 main 包的模块:
+// cmd/api 依赖 svc, cmd/tool 依赖 tools, svc/extra.go 仅在 extra 构建标签下参与构建
+var buildGraphFiles = map[string]string{
+	"cmd/api/main.go":  "package main\n\nimport (\n\t\"example.com/app/svc\"\n\t\"go.uber.org/zap\"\n)\n\nfunc main() {\n\tzap.L().Info(\"api\")\n\tsvc.Run()\n}\n",
+	"cmd/tool/main.go": "package main\n\nimport (\n\t\"example.com/app/tools\"\n\t\"go.uber.org/zap\"\n)\n\nfunc main() {\n\tzap.L().Info(\"tool\")\n\ttools.Run()\n}\n",
+	"svc/a.go":         "package svc\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n\tzap.L().Info(\"svc\")\n}\n",
+	"svc/extra.go":     "//go:build extra\n\npackage svc\n\nimport \"go.uber.org/zap\"\n\nfunc Extra() {\n\tzap.L().Info(\"extra\")\n}\n",
+	"tools/a.go":       "package tools\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n\tzap.L().Info(\"tools\")\n}\n",
+}
+
+// injectedFiles 返回 td 下包含注入字段的文件(相对路径)
+func injectedFiles(t *testing.T, td string) []string {
+	t.Helper()
+
+	var got []string
+
+	for _, name := range []string{"cmd/api/main.go", "cmd/tool/main.go", "svc/a.go", "svc/extra.go", "tools/a.go"} {
+		b, err := os.ReadFile(filepath.Join(td, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+
+		if strings.Contains(string(b), `zap.String("fl", "`+name+`:`) {
+			got = append(got, name)
+		}
+	}
+
+	return got
+}
+
+// TestBuildGraph_InjectsOnlySelectedMains 测试 -for 只处理所选 main 包构建依赖图中的文件, 并遵循 -tags
+func TestBuildGraph_InjectsOnlySelectedMains(t *testing.T) {
+	td := writeTree(t, zapModule(t), buildGraphFiles)
+
+	for _, c := range []struct {
+		tags string
+		want string
+	}{
+		{"", "cmd/api/main.go,svc/a.go"},
+		{"extra", "cmd/api/main.go,svc/a.go,svc/extra.go"},
+	} {
+		resetGlobals()
+
+		*pathFlag = td
+		*writeFlg = true
+		*fieldFlg = "fl"
+		*forFlg = "./cmd/api"
+		*tagsFlg = c.tags
+		os.Args = []string{"cmd"}
+
+		_ = captureOutput(func() { main() })
+
+		if got := strings.Join(injectedFiles(t, td), ","); got != c.want {
+			t.Fatalf("tags %q: injected %s, want %s", c.tags, got, c.want)
+		}
+	}
+
+	resetGlobals()
+}
+
+// TestInitBuildGraph_Error 测试无法解析的包返回错误
+func TestInitBuildGraph_Error(t *testing.T) {
+	td := writeTree(t, zapModule(t), buildGraphFiles)
+
+	resetGlobals()
+	defer resetGlobals()
+
+	*forFlg = "./cmd/missing"
+
+	if err := initBuildGraph(td); err == nil {
+		t.Fatalf("expected error for missing package")
+	}
+
+	if forFiles != nil {
+		t.Fatalf("expected build graph disabled after error")
+	}
+}
@@ -0,0 +1,75 @@
+//
+// FilePath    : zap-smap\collision.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 与注入字段同名的已有字段的冲突检测, 避免覆盖开发者自己记录的数据
+//
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// 注入值的形态: file:line 与 -with-func 的 file:line | func, 以及 -token 模式的令牌。
+// 文件路径可以包含空格, 函数名不含空格
+var (
+	siteValuePattern  = regexp.MustCompile(`^\S.*?\.go:\d+( \| \S+)?$`)
+	tokenValuePattern = regexp.MustCompile(fmt.Sprintf(`^[0-9a-f]{%d}$`, tokenLen))
+)
+
+// looksInjected 判断字符串是否具有工具注入的位置形态, 与本次运行是否启用 -with-func、-token 无关,
+// 以便切换模式后仍能识别并更新此前注入的字段
+func looksInjected(v string) bool {
+	return siteValuePattern.MatchString(v) || tokenValuePattern.MatchString(v)
+}
+
+// collidingField 返回调用中键为 -field 但不是由工具注入的字段(构造函数不是 String, 或值不是注入的位置)的描述,
+// 不存在冲突时返回空串
+func collidingField(lc logCall) string {
+	if lc.isEllipsis() {
+		_, fieldCall, _ := findEllipsisFieldCall(lc.b, lc.ce.Args[len(lc.ce.Args)-1], *fieldFlg)
+		if fieldCall == nil || lc.b.isInjectedField(fieldCall) {
+			return ""
+		}
+
+		return types.ExprString(fieldCall)
+	}
+
+	f, ok := findField(lc, *fieldFlg)
+	if !ok || isOwnedField(lc, f) {
+		return ""
+	}
+
+	if f.isPair() {
+		return fmt.Sprintf("%q, %s", f.key, types.ExprString(f.value))
+	}
+
+	// 调用链形式只显示字段方法本身, 例如 .Int("fl", floor)
+	if sel, ok := f.call.Fun.(*ast.SelectorExpr); ok && lc.b.isChainStyle() {
+		args := make([]string, len(f.call.Args))
+		for i, a := range f.call.Args {
+			args[i] = types.ExprString(a)
+		}
+
+		return fmt.Sprintf(".%s(%s)", sel.Sel.Name, strings.Join(args, ", "))
+	}
+
+	return types.ExprString(f.call)
+}
+
+// collisionIssue 构造冲突的问题描述, 写回时用作警告, 校验时用作问题
+func collisionIssue(lc logCall, rel string, line int, field string) string {
+	return fmt.Sprintf("%s:%d: %s.%s field '%s' collides with %s, which is not an injected location", rel, line, lc.b.ident, lc.method, *fieldFlg, field)
+}
+
+// warnCollision 输出写回时跳过冲突字段的警告
+func warnCollision(lc logCall, rel string, line int, field string) {
+	fmt.Fprintf(os.Stderr, "warn: %s; skipped (use -force to overwrite)\n", collisionIssue(lc, rel, line, field))
+}
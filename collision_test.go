@@ -0,0 +1,146 @@
+//
+// FilePath    : zap-smap\collision_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 同名字段冲突检测单测
+//
+
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLooksInjected 测试注入位置形态的识别
+func TestLooksInjected(t *testing.T) {
+	cases := map[string]bool{
+		"svc/a.go:12":                             true,
+		"a.go:3 | example.com/app/svc.Run":        true,
+		"a.go:3 | example.com/app/svc.(*T).Run":   true,
+		"my dir/a.go:3":                           true,
+		"my dir/a.go:3 | example.com/app/svc.Run": true,
+		"0123456789abcdef":                        true,
+		"":                                        false,
+		"floor-3":                                 false,
+		"a.go":                                    false,
+		"svc/a.go:12 extra":                       false,
+		"0123456789ABCDEF":                        false,
+		"a.go:3 |":                                false,
+		"request from svc/a.go:12 | handler done": false,
+	}
+
+	for v, want := range cases {
+		if got := looksInjected(v); got != want {
+			t.Fatalf("looksInjected(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+// collisionSrc 包含三种冲突字段与一条已注入字段的源码
+const collisionSrc = `package app
+
+import (
+	"log/slog"
+
+	"go.uber.org/zap"
+)
+
+func Run(floor int, fields []zap.Field) {
+	zap.L().Info("int", zap.Int("fl", floor))
+	zap.L().Info("str", zap.String("fl", "ground"))
+	slog.Info("pair", "fl", floor)
+	zap.L().Error("ellipsis", append([]zap.Field{zap.Int("fl", floor)}, fields...)...)
+	zap.L().Info("ours", zap.String("fl", "a.go:1"))
+}
+`
+
+// TestCollision_SkippedUnlessForced 测试冲突字段默认保持不变并输出警告, -force 时改写为注入字段
+func TestCollision_SkippedUnlessForced(t *testing.T) {
+	for _, force := range []bool{false, true} {
+		resetGlobals()
+
+		td := t.TempDir()
+		writeFile(t, td, "a.go", collisionSrc)
+
+		*writeFlg = true
+		*fieldFlg = "fl"
+		*forceFlg = force
+
+		out := captureOutput(func() {
+			if err := runDirectoryMode(td, token.NewFileSet(), "example.com/app", td); err != nil {
+				t.Errorf("run: %v", err)
+			}
+		})
+
+		b, _ := os.ReadFile(filepath.Join(td, "a.go"))
+		got := string(b)
+
+		if !strings.Contains(got, `zap.L().Info("ours", zap.String("fl", "a.go:14"))`) {
+			t.Fatalf("force=%v: expected own field updated, got:\n%s", force, got)
+		}
+
+		kept := []string{
+			`zap.Int("fl", floor))`,
+			`zap.String("fl", "ground")`,
+			`"fl", floor)`,
+			`append([]zap.Field{zap.Int("fl", floor)}, fields...)`,
+		}
+
+		for _, k := range kept {
+			if strings.Contains(got, k) == force {
+				t.Fatalf("force=%v: unexpected state of %s, got:\n%s", force, k, got)
+			}
+		}
+
+		if !force {
+			if n := strings.Count(out, "(use -force to overwrite)"); n != 4 {
+				t.Fatalf("expected 4 collision warnings, got %d:\n%s", n, out)
+			}
+
+			if !strings.Contains(out, `a.go:10: zap.Info field 'fl' collides with zap.Int("fl", floor)`) {
+				t.Fatalf("expected collision warning, got:\n%s", out)
+			}
+
+			continue
+		}
+
+		for _, w := range []string{
+			`zap.L().Info("int", zap.String("fl", "a.go:10"))`,
+			`slog.Info("pair", "fl", "a.go:12")`,
+			`append([]zap.Field{zap.String("fl", "a.go:13")}, fields...)`,
+		} {
+			if !strings.Contains(got, w) {
+				t.Fatalf("expected %s, got:\n%s", w, got)
+			}
+		}
+	}
+
+	resetGlobals()
+}
+
+// TestCollision_ReportedInVerify 测试校验时冲突字段单独计数且校验不通过
+func TestCollision_ReportedInVerify(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	td := t.TempDir()
+	writeFile(t, td, "a.go", collisionSrc)
+
+	*verifyFlg = true
+	*fieldFlg = "fl"
+
+	out := captureOutput(func() {
+		_ = runDirectoryMode(td, token.NewFileSet(), "example.com/app", td)
+	})
+
+	for _, w := range []string{"collision: 4", "mismatch: 1", `collides with "fl", floor`, "All injections look correct"} {
+		if strings.Contains(out, w) == (w == "All injections look correct") {
+			t.Fatalf("unexpected presence of %q, got:\n%s", w, out)
+		}
+	}
+}
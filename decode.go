@@ -0,0 +1,76 @@
+//
+// FilePath    : zap-smap\decode.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : decode 子命令, 将日志流中的位置令牌还原为源码位置
+//
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+// tokenPattern 匹配日志中的位置令牌
+var tokenPattern = regexp.MustCompile(fmt.Sprintf(`\b[0-9a-f]{%d}\b`, tokenLen))
+
+// runDecode 从 in 逐行读取 JSON 或 console 格式的日志, 将映射文件中存在的令牌替换为 "file:line | func" 后写到 out
+func runDecode(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("decode", flag.ContinueOnError)
+	smap := fs.String("smap", "smap.json", "令牌映射文件")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sm, err := loadSourceMap(*smap)
+	if err != nil {
+		return err
+	}
+
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	w := bufio.NewWriter(out)
+
+	for sc.Scan() {
+		if _, err := fmt.Fprintln(w, decodeLine(sc.Text(), sm.Entries)); err != nil {
+			return err
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// decodeLine 替换一行日志中的令牌, 以 '{' 开头的 JSON 日志对替换内容做 JSON 字符串转义
+func decodeLine(line string, entries map[string]smaprt.Site) string {
+	isJSON := strings.HasPrefix(strings.TrimSpace(line), "{")
+
+	return tokenPattern.ReplaceAllStringFunc(line, func(tok string) string {
+		loc, ok := entries[tok]
+		if !ok {
+			return tok
+		}
+
+		if !isJSON {
+			return loc.String()
+		}
+
+		b, _ := json.Marshal(loc.String())
+
+		return string(b[1 : len(b)-1])
+	})
+}
@@ -0,0 +1,330 @@
+//
+// FilePath    : zap-smap\descriptor.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 解析 backend 描述文件并编译为 backend
+//
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"strings"
+
+	"github.com/jiaopengzi/go-utils"
+)
+
+// builtinConfig 内置的 backend 描述(zap、log/slog、zerolog)
+//
+//go:embed backends.json
+var builtinConfig []byte
+
+// builtinBackends 由内置描述编译得到的 backend
+var builtinBackends = mustParseBackendConfig(builtinConfig)
+
+// activeBackends 当前生效的 backend, 由 loadBackends 根据 -backends 参数设置
+var activeBackends = builtinBackends
+
+// loadBackends 加载 -backends 指定的描述文件, 与内置 backend 合并为 activeBackends。
+// 自定义描述的导入路径与内置描述相同时替换内置描述
+func loadBackends() error {
+	activeBackends = builtinBackends
+
+	if *backendsFlg == "" {
+		return nil
+	}
+
+	data, err := utils.ReadFile(*backendsFlg)
+	if err != nil {
+		return fmt.Errorf("read -backends file: %w", err)
+	}
+
+	custom, err := parseBackendConfig(data)
+	if err != nil {
+		return fmt.Errorf("-backends %s: %w", *backendsFlg, err)
+	}
+
+	merged := make([]*backend, 0, len(builtinBackends)+len(custom))
+
+	for _, b := range builtinBackends {
+		if !containsImport(custom, b.importPath) {
+			merged = append(merged, b)
+		}
+	}
+
+	activeBackends = append(merged, custom...)
+
+	return nil
+}
+
+// containsImport 判断 bs 中是否有导入路径为 importPath 的 backend
+func containsImport(bs []*backend, importPath string) bool {
+	for _, b := range bs {
+		if b.importPath == importPath {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mustParseBackendConfig 解析内置描述, 内置描述有误属于程序错误, 直接 panic
+func mustParseBackendConfig(data []byte) []*backend {
+	bs, err := parseBackendConfig(data)
+	if err != nil {
+		panic(fmt.Sprintf("builtin backends.json: %v", err))
+	}
+
+	return bs
+}
+
+// parseBackendConfig 解析 JSON 格式的 backend 描述文件并逐个编译
+func parseBackendConfig(data []byte) ([]*backend, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var cfg backendConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	bs := make([]*backend, 0, len(cfg.Backends))
+
+	for i, d := range cfg.Backends {
+		b, err := compileDescriptor(d)
+		if err != nil {
+			return nil, fmt.Errorf("backend #%d (%s): %w", i+1, d.Import, err)
+		}
+
+		bs = append(bs, b)
+	}
+
+	return bs, nil
+}
+
+// compileDescriptor 校验描述并编译为 backend
+func compileDescriptor(d backendDescriptor) (*backend, error) {
+	if d.Import == "" {
+		return nil, fmt.Errorf("import is required")
+	}
+
+	b := &backend{
+		importPath:    d.Import,
+		rootFuncs:     make(map[string]bool),
+		rootVars:      make(map[string]bool),
+		deriveMethods: toSet(d.Derive),
+		methods:       make(map[string]methodSpec),
+		levelPkgs:     toSet(d.LevelPkgs),
+		levelConsts:   make(map[string]string),
+		sliceType:     d.SliceType,
+		kvPairs:       d.KVPairs,
+		checkWrite:    d.CheckWrite,
+	}
+
+	if b.sliceType == "" {
+		b.sliceType = "any"
+	}
+
+	if len(d.ChainEnds) > 0 {
+		b.chainEnds = toSet(d.ChainEnds)
+	}
+
+	if err := compileRoots(b, d.Roots); err != nil {
+		return nil, err
+	}
+
+	if err := compileFieldTemplate(b, d.Field); err != nil {
+		return nil, err
+	}
+
+	if err := compileMethods(b, d); err != nil {
+		return nil, err
+	}
+
+	for name, level := range d.LevelConsts {
+		l := canonicalLevelName(level)
+		if l == "" {
+			return nil, fmt.Errorf("levelConsts: unknown level %q for %s", level, name)
+		}
+
+		b.levelConsts[name] = l
+	}
+
+	if b.isChainStyle() && (b.kvPairs || b.checkWrite) {
+		return nil, fmt.Errorf("kvPairs and checkWrite are not supported with chainEnds")
+	}
+
+	b.sortFuncs = toSet(d.SortFuncs)
+	if len(b.sortFuncs) == 0 {
+		b.sortFuncs = map[string]bool{b.fieldFunc: true}
+	}
+
+	return b, nil
+}
+
+// compileRoots 解析调用链起点表达式: pkg 表示包级函数, pkg.Var 表示包级变量, pkg.Func(_) 表示函数调用(参数忽略)。
+// 所有起点必须属于同一个包标识符, 该标识符即 backend 的 ident
+func compileRoots(b *backend, roots []string) error {
+	if len(roots) == 0 {
+		return fmt.Errorf("roots is required")
+	}
+
+	for _, r := range roots {
+		expr, err := parser.ParseExpr(r)
+		if err != nil {
+			return fmt.Errorf("root %q: %w", r, err)
+		}
+
+		var ident string
+
+		switch v := expr.(type) {
+		case *ast.Ident:
+			ident = v.Name
+			b.pkgFuncs = true
+		case *ast.SelectorExpr:
+			id, ok := v.X.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("root %q: expected pkg.Var", r)
+			}
+
+			ident = id.Name
+			b.rootVars[v.Sel.Name] = true
+		case *ast.CallExpr:
+			sel, ok := v.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return fmt.Errorf("root %q: expected pkg.Func(...)", r)
+			}
+
+			id, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("root %q: expected pkg.Func(...)", r)
+			}
+
+			ident = id.Name
+			b.rootFuncs[sel.Sel.Name] = true
+		default:
+			return fmt.Errorf("root %q: expected pkg, pkg.Var or pkg.Func(...)", r)
+		}
+
+		if b.ident != "" && b.ident != ident {
+			return fmt.Errorf("root %q: all roots must use package %q", r, b.ident)
+		}
+
+		b.ident = ident
+	}
+
+	return nil
+}
+
+// compileFieldTemplate 解析字段构造模板, 模板必须是 pkg.Func(%q, %q) 或链式的 .Func(%q, %q), 两个 %q 依次为 key 与 value
+func compileFieldTemplate(b *backend, tmpl string) error {
+	if tmpl == "" {
+		return fmt.Errorf("field is required")
+	}
+
+	if strings.Count(tmpl, "%q") != 2 {
+		return fmt.Errorf("field %q: expected a call with exactly (%%q, %%q) arguments", tmpl)
+	}
+
+	const keyProbe, valProbe = "__key__", "__value__"
+
+	src := fmt.Sprintf(tmpl, keyProbe, valProbe)
+
+	chain := len(src) > 0 && src[0] == '.'
+	if chain != b.isChainStyle() {
+		return fmt.Errorf("field %q: chain backends use .Func(%%q, %%q), others use pkg.Func(%%q, %%q)", tmpl)
+	}
+
+	if chain {
+		// 链式模板没有接收者, 补一个占位接收者后再解析
+		src = "_" + src
+	}
+
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return fmt.Errorf("field %q: %w", tmpl, err)
+	}
+
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 || parseLitKey(call.Args[0]) != keyProbe || parseLitKey(call.Args[1]) != valProbe {
+		return fmt.Errorf("field %q: expected a call with exactly (%%q, %%q) arguments", tmpl)
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return fmt.Errorf("field %q: expected pkg.Func(%%q, %%q)", tmpl)
+	}
+
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return fmt.Errorf("field %q: expected pkg.Func(%%q, %%q)", tmpl)
+	}
+
+	if !chain {
+		b.fieldIdent = id.Name
+	}
+
+	b.fieldFunc = sel.Sel.Name
+
+	return nil
+}
+
+// compileMethods 由 levels 与 methods 构造日志方法表
+func compileMethods(b *backend, d backendDescriptor) error {
+	if len(d.Levels) == 0 {
+		return fmt.Errorf("levels is required")
+	}
+
+	if d.MsgIndex < 0 {
+		return fmt.Errorf("msgIndex must not be negative")
+	}
+
+	for name, level := range d.Levels {
+		l := canonicalLevelName(level)
+		if l == "" {
+			return fmt.Errorf("levels: unknown level %q for method %s", level, name)
+		}
+
+		b.methods[name] = methodSpec{level: l, msgIndex: d.MsgIndex, sliceType: b.sliceType}
+	}
+
+	for name, m := range d.Methods {
+		spec := methodSpec{msgIndex: m.MsgIndex, levelArg: m.LevelArg, sliceType: m.SliceType, attrsOnly: m.AttrsOnly}
+
+		if spec.sliceType == "" {
+			spec.sliceType = b.sliceType
+		}
+
+		if m.Level != "" {
+			if spec.level = canonicalLevelName(m.Level); spec.level == "" {
+				return fmt.Errorf("methods.%s: unknown level %q", name, m.Level)
+			}
+		} else if m.LevelArg < 0 || m.LevelArg == m.MsgIndex {
+			return fmt.Errorf("methods.%s: levelArg %d must be a non-negative index other than msgIndex", name, m.LevelArg)
+		}
+
+		if m.MsgIndex < 0 {
+			return fmt.Errorf("methods.%s: msgIndex must not be negative", name)
+		}
+
+		b.methods[name] = spec
+	}
+
+	return nil
+}
+
+// toSet 将字符串列表转换为集合
+func toSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, s := range list {
+		set[s] = true
+	}
+
+	return set
+}
@@ -0,0 +1,145 @@
+//
+// FilePath    : zap-smap\descriptor_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : backend 描述文件解析与自定义 backend 单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuiltinBackends_ZapDescriptor 测试内置 zap 描述编译后的关键属性
+func TestBuiltinBackends_ZapDescriptor(t *testing.T) {
+	var zap *backend
+
+	for _, b := range builtinBackends {
+		if b.importPath == "go.uber.org/zap" {
+			zap = b
+		}
+	}
+
+	if zap == nil {
+		t.Fatalf("expected builtin zap backend")
+	}
+
+	if zap.ident != "zap" || !zap.rootFuncs["L"] || zap.fieldIdent != "zap" || zap.fieldFunc != "String" {
+		t.Fatalf("unexpected zap backend: %+v", zap)
+	}
+
+	if spec := zap.methods["Log"]; spec.level != "" || spec.levelArg != 0 || spec.msgIndex != 1 || spec.sliceType != "zap.Field" {
+		t.Fatalf("unexpected Log method spec: %+v", spec)
+	}
+
+	if spec := zap.methods["DPanic"]; spec.level != "DPanic" || spec.msgIndex != 0 {
+		t.Fatalf("unexpected DPanic method spec: %+v", spec)
+	}
+}
+
+// TestParseBackendConfig_Errors 测试描述文件的校验错误
+func TestParseBackendConfig_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"unknown key", `{"backends":[{"import":"x","bogus":1}]}`, "unknown field"},
+		{"missing import", `{"backends":[{"roots":["x"]}]}`, "import is required"},
+		{"missing roots", `{"backends":[{"import":"x","field":"x.S(%q, %q)","levels":{"Info":"Info"}}]}`, "roots is required"},
+		{"mixed roots", `{"backends":[{"import":"x","roots":["x.L()","y"],"field":"x.S(%q, %q)","levels":{"Info":"Info"}}]}`, "all roots must use package"},
+		{"bad root", `{"backends":[{"import":"x","roots":["x[0]"],"field":"x.S(%q, %q)","levels":{"Info":"Info"}}]}`, "expected pkg"},
+		{"bad template", `{"backends":[{"import":"x","roots":["x"],"field":"x.S(%q)","levels":{"Info":"Info"}}]}`, "exactly (%q, %q)"},
+		{"chain template", `{"backends":[{"import":"x","roots":["x"],"field":"x.S(%q, %q)","levels":{"Info":"Info"},"chainEnds":["Msg"]}]}`, "chain backends use"},
+		{"unknown level", `{"backends":[{"import":"x","roots":["x"],"field":"x.S(%q, %q)","levels":{"Trace":"Trace"}}]}`, `unknown level "Trace"`},
+		{"levelArg", `{"backends":[{"import":"x","roots":["x"],"field":"x.S(%q, %q)","levels":{"Info":"Info"},"methods":{"Log":{"levelArg":1,"msgIndex":1}}}]}`, "levelArg 1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseBackendConfig([]byte(c.json))
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("expected error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+// logxBackends 描述内部日志库 logx 的 backend 文件
+const logxBackends = `{
+  "backends": [
+    {
+      "import": "example.com/infra/logx",
+      "roots": ["logx.Get(_)"],
+      "derive": ["Tag"],
+      "levels": {"Infof": "Info", "Errorf": "Error"},
+      "msgIndex": 1,
+      "field": "logx.Str(%q, %q)",
+      "sliceType": "logx.Field"
+    }
+  ]
+}`
+
+// TestMain_CustomBackend_InjectAndVerify 测试通过 -backends 描述的内部 logger 被注入与校验
+func TestMain_CustomBackend_InjectAndVerify(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "logx.json", logxBackends)
+
+	src := filepath.Join(td, "src")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	writeFile(t, src, "svc.go", `package svc
+
+import "example.com/infra/logx"
+
+func Run(ctx any, fields []logx.Field) {
+	l := logx.Get(ctx).Tag("svc")
+
+	l.Infof(ctx, "started", logx.Int("n", 1))
+	logx.Get(ctx).Errorf(ctx, "failed", fields...)
+}
+`)
+
+	*pathFlag = src
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*backendsFlg = filepath.Join(td, "logx.json")
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	b, err := os.ReadFile(filepath.Join(src, "svc.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	s := string(b)
+
+	wants := []string{
+		`l.Infof(ctx, "started", logx.Str("fl", "svc.go:8"), logx.Int("n", 1))`,
+		`logx.Get(ctx).Errorf(ctx, "failed", append([]logx.Field{logx.Str("fl", "svc.go:9")}, fields...)...)`,
+	}
+	for _, w := range wants {
+		if !strings.Contains(s, w) {
+			t.Fatalf("expected %s, got:\n%s", w, s)
+		}
+	}
+
+	*writeFlg = false
+	*verifyFlg = true
+
+	out := captureOutput(func() { main() })
+	if !strings.Contains(out, "total calls: 2") || !strings.Contains(out, "All injections look correct.") {
+		t.Fatalf("expected custom backend calls verified, got:\n%s", out)
+	}
+}
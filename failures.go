@@ -0,0 +1,83 @@
+//
+// FilePath    : zap-smap\failures.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 单个文件的读取、解析与写回失败, 以及 -continue-on-error 模式的汇总报告
+//
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// 文件失败的操作类型
+const (
+	opRead  = "read"
+	opParse = "parse"
+	opWrite = "write"
+	opCheck = "type-check"
+)
+
+// fileError 处理单个文件时的失败及其原因
+type fileError struct {
+	op   string // opRead、opParse、opWrite 或 opCheck
+	path string
+	err  error
+}
+
+func (e *fileError) Error() string {
+	return fmt.Sprintf("%s %s failed: %v", e.op, e.path, e.err)
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+// failures -continue-on-error 模式下收集的失败, 按发生顺序排列
+var failures []error
+
+// handleFileError 处理单个文件的失败: -continue-on-error 时记录并继续;
+// 否则解析失败与类型检查拒绝写回只输出警告并跳过该文件(不中断运行), 其余失败中断运行
+func handleFileError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if *contFlg {
+		failures = append(failures, err)
+		return nil
+	}
+
+	var fe *fileError
+	if errors.As(err, &fe) && (fe.op == opParse || fe.op == opCheck) {
+		fmt.Fprintf(os.Stderr, "warn: %v\n", err)
+		return nil
+	}
+
+	return err
+}
+
+// isParseError 判断 err 是否为文件解析失败
+func isParseError(err error) bool {
+	var fe *fileError
+	return errors.As(err, &fe) && fe.op == opParse
+}
+
+// reportFailures 打印收集的全部失败, 存在失败时返回错误使进程以非零状态退出
+func reportFailures() error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n===== ERRORS (%d) =====\n", len(failures))
+
+	for _, err := range failures {
+		fmt.Printf("  ✗ %v\n", err)
+	}
+
+	return fmt.Errorf("%d files failed", len(failures))
+}
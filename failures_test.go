@@ -0,0 +1,89 @@
+//
+// FilePath    : zap-smap\failures_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -continue-on-error 单测
+//
+
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failureFiles 包含一个无法解析的文件与一个正常文件的仓库
+var failureFiles = map[string]string{
+	"go.mod": "module example.com/app\n",
+	"bad.go": "package app\n\nfunc Broken( {\n",
+	"good.go": `package app
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("run")
+}
+`,
+}
+
+// TestContinueOnError_CollectsFailures 测试遇到无法解析的文件时继续处理其余文件, 最后汇总失败
+func TestContinueOnError_CollectsFailures(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	td := writeTree(t, failureFiles)
+
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*contFlg = true
+
+	var err error
+
+	out := captureOutput(func() {
+		if err = runDirectoryMode(td, token.NewFileSet(), "example.com/app", td); err != nil {
+			return
+		}
+
+		err = reportFailures()
+	})
+
+	if err == nil || err.Error() != "1 files failed" {
+		t.Fatalf("expected aggregated failure, got %v", err)
+	}
+
+	if !strings.Contains(out, "===== ERRORS (1) =====") || !strings.Contains(out, "parse "+filepath.Join(td, "bad.go")+" failed") {
+		t.Fatalf("expected error section, got:\n%s", out)
+	}
+
+	b, _ := os.ReadFile(filepath.Join(td, "good.go"))
+	if !strings.Contains(string(b), `zap.String("fl", "good.go:6")`) {
+		t.Fatalf("expected good.go injected, got:\n%s", b)
+	}
+}
+
+// TestVerify_CountsUnparseableAsFailed 测试校验时无法解析的文件计为失败, 而不是没有日志调用
+func TestVerify_CountsUnparseableAsFailed(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	td := writeTree(t, failureFiles)
+
+	*verifyFlg = true
+	*fieldFlg = "fl"
+
+	out := captureOutput(func() {
+		_ = runDirectoryMode(td, token.NewFileSet(), "example.com/app", td)
+	})
+
+	if !strings.Contains(out, "failed: 1") {
+		t.Fatalf("expected failed count, got:\n%s", out)
+	}
+
+	if strings.Contains(out, "All injections look correct") {
+		t.Fatalf("expected verify to fail, got:\n%s", out)
+	}
+}
@@ -0,0 +1,73 @@
+//
+// FilePath    : zap-smap\filter.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : filter 子命令, 作为 git clean/smudge 过滤器使仓库中不保存注入字段
+//
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// 过滤器模式
+const (
+	filterClean  = "clean"  // git add 时去除注入字段
+	filterSmudge = "smudge" // 检出时重新注入
+)
+
+// runFilter git 过滤器, 在 git config 中配置为 zap-smap filter clean %f 与 zap-smap filter smudge %f:
+// 从 in 读取文件内容, clean 只去除由工具注入的字段(开发者自己记录的同名字段保持不变), smudge 按 %f 对应的位置重新注入,
+// 结果写入 out; -token 与 -locs 的注入值与主命令一致。
+// 内容无法解析或处理失败时原样输出, 不阻断 git 操作
+func runFilter(args []string, in io.Reader, out io.Writer) error {
+	if len(args) == 0 || (args[0] != filterClean && args[0] != filterSmudge) {
+		return fmt.Errorf("usage: zap-smap filter clean|smudge [flags] %%f")
+	}
+
+	mode := args[0]
+
+	fs := flag.NewFlagSet("filter "+mode, flag.ContinueOnError)
+	bindFormatFlags(fs)
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: zap-smap filter %s [flags] %%f", mode)
+	}
+
+	if err := initFormatModes(); err != nil {
+		return err
+	}
+
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	res := src
+
+	if mode == filterClean {
+		res = stripInjected(path, src)
+	} else {
+		baseDir := *pathFlag
+
+		if res, err = injectSource(path, src, readModulePath(baseDir), baseDir); err != nil {
+			fmt.Fprintf(os.Stderr, "warn: smudge %s failed: %v\n", path, err)
+
+			res = src
+		}
+	}
+
+	_, err = out.Write(res)
+
+	return err
+}
@@ -0,0 +1,155 @@
+//
+// FilePath    : zap-smap\filter_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : filter 子命令单测
+//
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// filterCleanSample 去除注入字段后的源码, Info 调用位于第 6 行
+const filterCleanSample = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("started", zap.Int("n", 1))
+}
+`
+
+// TestRunFilter_CleanSmudge 测试 clean 去除注入字段、smudge 按 %f 重新注入
+func TestRunFilter_CleanSmudge(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	// git 在仓库根目录运行过滤器, %f 相对于仓库根
+	t.Chdir(t.TempDir())
+
+	injected := strings.Replace(filterCleanSample, `zap.Int("n", 1)`, `zap.String("fl", "svc/a.go:6"), zap.Int("n", 1)`, 1)
+
+	var out bytes.Buffer
+
+	if err := runFilter([]string{"smudge", "-field", "fl", "svc/a.go"}, strings.NewReader(filterCleanSample), &out); err != nil {
+		t.Fatalf("smudge: %v", err)
+	}
+
+	if out.String() != injected {
+		t.Fatalf("unexpected smudge output:\n%s\nwant:\n%s", out.String(), injected)
+	}
+
+	out.Reset()
+
+	if err := runFilter([]string{"clean", "-field", "fl", "svc/a.go"}, strings.NewReader(injected), &out); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+
+	if out.String() != filterCleanSample {
+		t.Fatalf("unexpected clean output:\n%s\nwant:\n%s", out.String(), filterCleanSample)
+	}
+}
+
+// TestRunFilter_KeepsDeveloperField 测试 clean 不去除开发者自己记录的同名字段, clean→smudge 后字段保持不变
+func TestRunFilter_KeepsDeveloperField(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	t.Chdir(t.TempDir())
+
+	src := strings.Replace(filterCleanSample, `zap.Int("n", 1)`, `zap.Int("fl", floor)`, 1)
+
+	var cleaned, smudged bytes.Buffer
+
+	out := captureOutput(func() {
+		if err := runFilter([]string{"clean", "-field", "fl", "svc/a.go"}, strings.NewReader(src), &cleaned); err != nil {
+			t.Errorf("clean: %v", err)
+		}
+
+		if err := runFilter([]string{"smudge", "-field", "fl", "svc/a.go"}, strings.NewReader(cleaned.String()), &smudged); err != nil {
+			t.Errorf("smudge: %v", err)
+		}
+	})
+
+	if cleaned.String() != src || smudged.String() != src {
+		t.Fatalf("expected developer field kept, got clean:\n%s\nsmudge:\n%s", cleaned.String(), smudged.String())
+	}
+
+	if !strings.Contains(out, "(use -force to overwrite)") {
+		t.Fatalf("expected collision warning on smudge, got:\n%s", out)
+	}
+}
+
+// TestRunFilter_TokenMode 测试 -token 模式下 smudge 注入令牌, clean 去除令牌
+func TestRunFilter_TokenMode(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	t.Chdir(t.TempDir())
+
+	tokenKey = []byte("secret")
+	injected := strings.Replace(filterCleanSample, `zap.Int("n", 1)`, `zap.String("fl", "`+siteToken("svc/a.go", 6, "svc.Run")+`"), zap.Int("n", 1)`, 1)
+
+	args := []string{"-field", "fl", "-token", "-token-key", "secret", "svc/a.go"}
+
+	var out bytes.Buffer
+
+	if err := runFilter(append([]string{"smudge"}, args...), strings.NewReader(filterCleanSample), &out); err != nil {
+		t.Fatalf("smudge: %v", err)
+	}
+
+	if out.String() != injected {
+		t.Fatalf("unexpected smudge output:\n%s\nwant:\n%s", out.String(), injected)
+	}
+
+	out.Reset()
+
+	if err := runFilter(append([]string{"clean"}, args...), strings.NewReader(injected), &out); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+
+	if out.String() != filterCleanSample {
+		t.Fatalf("unexpected clean output:\n%s", out.String())
+	}
+}
+
+// TestRunFilter_PassThrough 测试无法解析的源码与非 Go 文件原样输出, 以及参数错误
+func TestRunFilter_PassThrough(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	cases := []struct {
+		mode, path, src string
+	}{
+		{"smudge", "svc/a.go", "package svc\n\nfunc Run() {\n\tzap.L().Info(\n"},
+		{"clean", "svc/a.go", "package svc\n\nfunc Run() {\n\tzap.L().Info(\n"},
+		{"smudge", "README.md", "zap.L().Info(\"x\")\n"},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+
+		captureOutput(func() {
+			if err := runFilter([]string{c.mode, c.path}, strings.NewReader(c.src), &out); err != nil {
+				t.Fatalf("%s %s: %v", c.mode, c.path, err)
+			}
+		})
+
+		if out.String() != c.src {
+			t.Fatalf("%s %s: expected content unchanged, got:\n%s", c.mode, c.path, out.String())
+		}
+	}
+
+	if err := runFilter([]string{"inject", "svc/a.go"}, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected usage error for unknown mode")
+	}
+
+	if err := runFilter([]string{"clean"}, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected usage error without %%f")
+	}
+}
@@ -11,8 +11,6 @@ package main
 import (
 	"flag"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
@@ -24,9 +22,28 @@ var (
 	writeFlg    = flag.Bool("write", false, "将修改写回文件")
 	funcFlg     = flag.Bool("with-func", false, "在注入内容中包含函数名")
 	verifyFlg   = flag.Bool("verify", false, "仅校验注入是否正确(不写回文件), 返回汇总报告")
-	excludeFlag = flag.String("exclude", "", "以逗号分隔的要排除的目录或文件路径")
+	excludeFlag = flag.String("exclude", "", "以逗号分隔的要排除的目录或文件, 支持 gitignore 风格的 glob(含 **), 例如 node_modules,cmd/tools/**,**/*_mock.go")
+	includeFlag = flag.String("include", "", "以逗号分隔的要处理的目录或文件 glob, 指定后只处理匹配的文件, 语法同 -exclude")
+	defaultsFlg = flag.String("default-excludes", defaultExcludes, "默认排除的目录与文件, 语法同 -exclude, 传入空串可取消全部默认排除")
+	ignoreFlg   = flag.Bool("gitignore", true, "跳过 .gitignore 忽略的路径(.zap-smapignore 总是生效)")
+	testsFlg    = flag.String("tests", testsInclude, "_test.go 文件的处理策略: include(处理)、skip(跳过)或 only(只处理 _test.go)")
 	positionFlg = flag.Int("position", -1, "插入字段的位置索引(0-based)相对于 field 参数列表(跳过 msg); 0=第一个 field 之前, 默认-1等同于0")
-	sortFlg     = flag.Bool("sort", false, "按字段键的字母顺序排列 zap 字段")
+	sortFlg     = flag.Bool("sort", false, "按字段键的字母顺序排列日志字段")
+	policyFlg   = flag.String("level-policy", "", "以逗号分隔的级别注入策略, 例如 Debug=skip,Info=strip; 取值 inject/skip/strip, 未列出的级别默认为 inject")
+	backendsFlg = flag.String("backends", "", "自定义日志库 backend 描述文件(JSON), 与内置的 zap、log/slog、zerolog 描述合并, 导入路径相同时替换内置描述")
+	tokenFlg    = flag.Bool("token", false, "注入源码位置的 HMAC 令牌代替 file:line, 并生成 -smap 指定的映射文件")
+	tokenKeyFlg = flag.String("token-key", "", "-token 模式使用的 HMAC 密钥, 未指定时读取环境变量 ZAP_SMAP_TOKEN_KEY")
+	smapFlg     = flag.String("smap", "smap.json", "-token 模式的令牌映射文件, 相对路径基于仓库根目录")
+	locsFlg     = flag.Bool("locs", false, "调用处引用生成的位置常量(smapLoc_xxx), 位置字符串写入每个包的 zz_smap_locs.go")
+	garbleFlg   = flag.Bool("garble-scope", false, "只在 garble 会混淆的包(匹配 GOGARBLE)中注入, 其余包保持不变")
+	gogarbleFlg = flag.String("gogarble", "", "-garble-scope 使用的 GOGARBLE 模式, 未指定时读取环境变量 GOGARBLE, 均为空时匹配全部包")
+	gStripFlg   = flag.Bool("garble-strip", false, "-garble-scope 模式下移除 GOGARBLE 范围之外的包中由工具注入的字段")
+	forFlg      = flag.String("for", "", "以逗号分隔的 main 包, 例如 ./cmd/api,./cmd/worker; 只处理其构建依赖图中属于本模块的文件")
+	tagsFlg     = flag.String("tags", "", "-for 解析构建依赖图与 -typecheck 使用的构建标签, 以逗号分隔, 同 go build -tags")
+	relToFlg    = flag.String("rel-to", relToPath, "注入路径的基准目录: path(-path 指定的目录)、module(文件所属 module 根目录)、workspace(go.work 所在目录)或 repo(仓库根目录)")
+	contFlg     = flag.Bool("continue-on-error", false, "遇到文件读取、解析或写回失败时继续处理其余文件, 结束时汇总全部失败并以非零状态退出")
+	forceFlg    = flag.Bool("force", false, "覆盖与注入字段同名但不是由工具注入的字段(例如 zap.Int(\"fl\", floor)), 默认跳过并输出警告")
+	typeChkFlg  = flag.Bool("typecheck", true, "写回前对改写后的包做类型检查, 改写会引入编译错误时拒绝写回该文件")
 	versionFlg  = flag.Bool("version", false, "输出版本信息并退出")
 )
 
@@ -35,8 +52,58 @@ func init() {
 	flag.BoolVar(versionFlg, "v", false, "输出版本信息并退出(同 -version)")
 }
 
-// excludeList 用户指定的排除路径列表
-var excludeList []string
+// bindFormatFlags 在子命令的 FlagSet 上注册决定注入值与字段形态的参数, 与主命令共用同一变量, 取值应与注入时一致
+func bindFormatFlags(fs *flag.FlagSet) {
+	fs.StringVar(pathFlag, "path", *pathFlag, "仓库根目录, 注入的位置相对于该目录")
+	fs.StringVar(fieldFlg, "field", *fieldFlg, "注入的字段名")
+	fs.BoolVar(funcFlg, "with-func", *funcFlg, "注入内容包含函数名")
+	fs.StringVar(policyFlg, "level-policy", *policyFlg, "级别注入策略")
+	fs.StringVar(backendsFlg, "backends", *backendsFlg, "自定义日志库 backend 描述文件")
+	fs.IntVar(positionFlg, "position", *positionFlg, "插入字段的位置索引")
+	fs.BoolVar(sortFlg, "sort", *sortFlg, "按字段键的字母顺序排列日志字段")
+	fs.BoolVar(garbleFlg, "garble-scope", *garbleFlg, "只在 garble 会混淆的包中注入")
+	fs.StringVar(gogarbleFlg, "gogarble", *gogarbleFlg, "-garble-scope 使用的 GOGARBLE 模式")
+	fs.BoolVar(gStripFlg, "garble-strip", *gStripFlg, "移除 GOGARBLE 范围之外的包中由工具注入的字段")
+	fs.StringVar(relToFlg, "rel-to", *relToFlg, "注入路径的基准目录: module、workspace、repo 或 path")
+	fs.BoolVar(forceFlg, "force", *forceFlg, "覆盖与注入字段同名但不是由工具注入的字段")
+	fs.BoolVar(tokenFlg, "token", *tokenFlg, "注入值为 HMAC 令牌")
+	fs.StringVar(tokenKeyFlg, "token-key", *tokenKeyFlg, "-token 模式使用的 HMAC 密钥, 未指定时读取环境变量 ZAP_SMAP_TOKEN_KEY")
+	fs.StringVar(smapFlg, "smap", *smapFlg, "-token 模式的令牌映射文件, 相对路径基于仓库根目录")
+	fs.BoolVar(locsFlg, "locs", *locsFlg, "调用处引用生成的位置常量")
+}
+
+// initFormatModes 按 bindFormatFlags 注册的参数初始化级别策略、backend、-token、-locs、-garble-scope 与 -rel-to,
+// 主命令与子命令共用, 保证生成的注入值一致
+func initFormatModes() error {
+	if err := parseLevelPolicy(); err != nil {
+		return err
+	}
+
+	if err := loadBackends(); err != nil {
+		return err
+	}
+
+	if err := initTokenMode(); err != nil {
+		return err
+	}
+
+	initLocsMode()
+	initGarbleScope()
+
+	return initRelTo()
+}
+
+// bindSkipFlags 在子命令的 FlagSet 上注册决定处理哪些文件的参数, 与主命令共用同一变量
+func bindSkipFlags(fs *flag.FlagSet) {
+	fs.StringVar(excludeFlag, "exclude", *excludeFlag, "以逗号分隔的要排除的目录或文件 glob")
+	fs.StringVar(includeFlag, "include", *includeFlag, "以逗号分隔的要处理的目录或文件 glob")
+	fs.StringVar(defaultsFlg, "default-excludes", *defaultsFlg, "默认排除的目录与文件")
+	fs.BoolVar(ignoreFlg, "gitignore", *ignoreFlg, "跳过 .gitignore 忽略的路径")
+	fs.StringVar(testsFlg, "tests", *testsFlg, "_test.go 文件的处理策略: include、skip 或 only")
+}
+
+// levelPolicy 由 -level-policy 解析得到的级别策略, key 为 levelNames 中的名称
+var levelPolicy map[string]string
 
 // checkFlagConflicts 检查命令行 flag 冲突
 func checkFlagConflicts() error {
@@ -60,30 +127,68 @@ func checkFlagConflicts() error {
 	return nil
 }
 
-// parseExcludeList 将 -exclude 参数解析为可匹配的条目
-func parseExcludeList(baseDir string) {
-	if *excludeFlag == "" {
-		return
+// parseLevelPolicy 将 -level-policy 参数解析为 levelPolicy, 级别名称不区分大小写
+func parseLevelPolicy() error {
+	levelPolicy = nil
+
+	if *policyFlg == "" {
+		return nil
 	}
 
-	parts := strings.SplitSeq(*excludeFlag, ",")
-	for p := range parts {
-		p = strings.TrimSpace(p)
-		if p == "" {
+	levelPolicy = make(map[string]string)
+
+	for item := range strings.SplitSeq(*policyFlg, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
 			continue
 		}
 
-		// 统一清理路径并使用 '/' 作为分隔符以便匹配
-		if strings.Contains(p, string(os.PathSeparator)) || strings.Contains(p, "/") {
-			if !filepath.IsAbs(p) {
-				p = filepath.Join(baseDir, p)
-			}
-
-			p = filepath.Clean(p)
-			excludeList = append(excludeList, filepath.ToSlash(p))
-		} else {
-			// 仅为简单名称(例如 node_modules)
-			excludeList = append(excludeList, p)
+		name, action, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("invalid -level-policy entry %q, expected <level>=<inject|skip|strip>", item)
+		}
+
+		level := canonicalLevelName(strings.TrimSpace(name))
+		if level == "" {
+			return fmt.Errorf("unknown level %q in -level-policy, valid levels: %s", name, strings.Join(levelNames, ", "))
 		}
+
+		action = strings.ToLower(strings.TrimSpace(action))
+		if action != policyInject && action != policySkip && action != policyStrip {
+			return fmt.Errorf("unknown policy %q for level %s in -level-policy, expected inject, skip or strip", action, level)
+		}
+
+		levelPolicy[level] = action
 	}
+
+	return nil
+}
+
+// canonicalLevelName 返回 name 在 levelNames 中对应的规范名称(不区分大小写), 未找到返回空串
+func canonicalLevelName(name string) string {
+	for _, l := range levelNames {
+		if strings.EqualFold(l, name) {
+			return l
+		}
+	}
+
+	return ""
+}
+
+// policyFor 返回 level 对应的注入策略, 未配置时默认为 inject
+func policyFor(level string) string {
+	if p, ok := levelPolicy[level]; ok {
+		return p
+	}
+
+	return policyInject
+}
+
+// policyFor 返回本文件中 level 对应的注入策略, 文件级策略优先于 -level-policy
+func (sc *fileScope) policyFor(level string) string {
+	if sc.policy != "" {
+		return sc.policy
+	}
+
+	return policyFor(level)
 }
@@ -58,3 +58,59 @@ func TestCheckFlagConflicts(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLevelPolicy(t *testing.T) {
+	t.Cleanup(resetGlobals)
+
+	cases := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"case-insensitive", "debug=skip, INFO=Strip", map[string]string{"Debug": policySkip, "Info": policyStrip}, false},
+		{"generic-log", "Log=inject", map[string]string{"Log": policyInject}, false},
+		{"unknown-level", "Trace=skip", nil, true},
+		{"unknown-policy", "Debug=drop", nil, true},
+		{"missing-equal", "Debug", nil, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			*policyFlg = tc.value
+
+			err := parseLevelPolicy()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q but got nil", tc.value)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(levelPolicy) != len(tc.want) {
+				t.Fatalf("policy mismatch: got %v want %v", levelPolicy, tc.want)
+			}
+
+			for k, v := range tc.want {
+				if levelPolicy[k] != v {
+					t.Fatalf("policy for %s: got %q want %q", k, levelPolicy[k], v)
+				}
+			}
+		})
+	}
+
+	*policyFlg = "Warn=skip"
+	if err := parseLevelPolicy(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if policyFor("Warn") != policySkip || policyFor("Error") != policyInject {
+		t.Fatalf("policyFor returned unexpected values: Warn=%s Error=%s", policyFor("Warn"), policyFor("Error"))
+	}
+}
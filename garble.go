@@ -0,0 +1,106 @@
+//
+// FilePath    : zap-smap\garble.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -garble-scope 模式, 按 GOGARBLE 限定处理的包
+//
+
+package main
+
+import (
+	"os"
+	pathpkg "path"
+	"strings"
+)
+
+// garbleEnv garble 读取的包范围环境变量
+const garbleEnv = "GOGARBLE"
+
+// garblePatterns -garble-scope 模式下的 GOGARBLE 模式列表, nil 表示未启用
+var garblePatterns []string
+
+// initGarbleScope 解析 -garble-scope 参数: 模式取自 -gogarble 或环境变量 GOGARBLE, 均为空时与 garble 一致匹配全部包
+func initGarbleScope() {
+	garblePatterns = nil
+
+	if !*garbleFlg {
+		return
+	}
+
+	v := *gogarbleFlg
+	if v == "" {
+		v = os.Getenv(garbleEnv)
+	}
+
+	if v == "" {
+		v = "*"
+	}
+
+	garblePatterns = []string{}
+
+	for p := range strings.SplitSeq(v, ",") {
+		if p = strings.TrimSuffix(strings.TrimSpace(p), "/"); p != "" {
+			garblePatterns = append(garblePatterns, p)
+		}
+	}
+}
+
+// matchPrefixPatterns 判断导入路径 target 是否匹配任一模式, 规则与 go 命令处理 GOPRIVATE、garble 处理 GOGARBLE 一致:
+// 模式为 path.Match 的 glob, 与 target 中段数相同的前缀比较, 例如 example.com/app 匹配 example.com/app/svc
+func matchPrefixPatterns(patterns []string, target string) bool {
+	for _, glob := range patterns {
+		n := strings.Count(glob, "/")
+		prefix := target
+
+		// 截取 target 的前 n+1 段
+		for i := 0; i < len(target); i++ {
+			if target[i] == '/' {
+				if n == 0 {
+					prefix = target[:i]
+					break
+				}
+
+				n--
+			}
+		}
+
+		// target 的段数不足
+		if n > 0 {
+			continue
+		}
+
+		if ok, _ := pathpkg.Match(glob, prefix); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// garbleImportPath 返回文件所在包的导入路径(见 fileImportPath), 没有 module path 时为文件相对仓库根的目录
+func garbleImportPath(path, modulePath, baseDir string) string {
+	rel := relPath(path, baseDir)
+
+	if importPath := fileImportPath(path, rel, modulePath); importPath != "" {
+		return importPath
+	}
+
+	return pathpkg.Dir(rel)
+}
+
+// applyGarbleScope 判断文件是否需要处理: 未启用 -garble-scope 或文件所在包匹配 GOGARBLE 时正常处理;
+// 范围之外的文件在 -garble-strip 时以 skip 策略移除工具注入的字段, 否则不处理(返回 false)
+func applyGarbleScope(sc *fileScope, path, modulePath, baseDir string) bool {
+	if garblePatterns == nil || matchPrefixPatterns(garblePatterns, garbleImportPath(path, modulePath, baseDir)) {
+		return true
+	}
+
+	if !*gStripFlg {
+		return false
+	}
+
+	sc.policy = policySkip
+
+	return true
+}
@@ -0,0 +1,135 @@
+//
+// FilePath    : zap-smap\garble_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -garble-scope 单测
+//
+
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMatchPrefixPatterns 测试 GOGARBLE 模式的前缀匹配规则
+func TestMatchPrefixPatterns(t *testing.T) {
+	cases := []struct {
+		patterns string
+		target   string
+		want     bool
+	}{
+		{"*", "example.com/app/svc", true},
+		{"example.com/app", "example.com/app", true},
+		{"example.com/app", "example.com/app/svc", true},
+		{"example.com/app", "example.com/application", false},
+		{"example.com/*/svc", "example.com/app/svc/sub", true},
+		{"example.com/*/svc", "example.com/app", false},
+		{"other.com,example.com/app/", "example.com/app/svc", true},
+		{"*.corp.com", "git.corp.com/x", true},
+	}
+
+	for _, c := range cases {
+		t.Setenv(garbleEnv, "")
+		resetGlobals()
+
+		*garbleFlg = true
+		*gogarbleFlg = c.patterns
+		initGarbleScope()
+
+		if got := matchPrefixPatterns(garblePatterns, c.target); got != c.want {
+			t.Fatalf("match(%q, %q) = %v, want %v", c.patterns, c.target, got, c.want)
+		}
+	}
+
+	resetGlobals()
+}
+
+// garbleFile 包 pkg 中一条已注入过期位置的日志
+func garbleFile(pkg string) string {
+	return "package " + pkg + `
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("run", zap.String("fl", "` + pkg + `/a.go:1"))
+}
+`
+}
+
+// garbleFiles 包含 svc 与 tools 两个包的仓库, 两个包各有一条已注入过期位置的日志
+var garbleFiles = map[string]string{
+	"go.mod":     "module example.com/app\n",
+	"svc/a.go":   garbleFile("svc"),
+	"tools/a.go": garbleFile("tools"),
+}
+
+// runGarbleMain 以 -garble-scope 运行主命令并返回两个包的文件内容
+func runGarbleMain(t *testing.T, td string, strip bool) (string, string) {
+	t.Helper()
+
+	resetGlobals()
+	defer resetGlobals()
+
+	t.Setenv(garbleEnv, "example.com/app/svc")
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*garbleFlg = true
+	*gStripFlg = strip
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	svc, _ := os.ReadFile(filepath.Join(td, "svc", "a.go"))
+	tools, _ := os.ReadFile(filepath.Join(td, "tools", "a.go"))
+
+	return string(svc), string(tools)
+}
+
+// TestGarbleScope_InjectsOnlyMatchingPackages 测试只处理 GOGARBLE 匹配的包, 以及 -garble-strip 移除范围之外的字段
+func TestGarbleScope_InjectsOnlyMatchingPackages(t *testing.T) {
+	td := writeTree(t, garbleFiles)
+
+	svc, tools := runGarbleMain(t, td, false)
+
+	if !strings.Contains(svc, `zap.String("fl", "svc/a.go:6")`) {
+		t.Fatalf("expected svc corrected, got:\n%s", svc)
+	}
+
+	if !strings.Contains(tools, `zap.String("fl", "tools/a.go:1")`) {
+		t.Fatalf("expected tools untouched, got:\n%s", tools)
+	}
+
+	_, tools = runGarbleMain(t, td, true)
+
+	if strings.Contains(tools, `"fl"`) {
+		t.Fatalf("expected field removed outside scope, got:\n%s", tools)
+	}
+
+	// 校验时范围之外的包不计入, 残留字段报告为 unexpected
+	resetGlobals()
+	defer resetGlobals()
+
+	writeFile(t, filepath.Join(td, "tools"), "a.go", "package tools\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n\tzap.L().Info(\"run\", zap.String(\"fl\", \"tools/a.go:6\"))\n}\n")
+
+	*garbleFlg = true
+	*gogarbleFlg = "example.com/app/svc"
+	*gStripFlg = true
+	*fieldFlg = "fl"
+	initGarbleScope()
+
+	vr, err := verifyFile(filepath.Join(td, "tools", "a.go"), token.NewFileSet(), "example.com/app", td)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if vr.total != 0 || vr.unexpected != 1 || !strings.Contains(vr.issues[0], "outside GOGARBLE scope") {
+		t.Fatalf("unexpected verify result: %+v", vr)
+	}
+}
@@ -2,7 +2,10 @@ module github.com/jiaopengzi/zap-smap
 
 go 1.25.6
 
-require github.com/jiaopengzi/go-utils v0.8.1
+require (
+	github.com/jiaopengzi/go-utils v0.8.1
+	go.uber.org/zap v1.27.1
+)
 
 require (
 	github.com/bytedance/gopkg v0.1.3 // indirect
@@ -41,7 +44,6 @@ require (
 	go.opentelemetry.io/otel/metric v1.39.0 // indirect
 	go.opentelemetry.io/otel/trace v1.39.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	go.uber.org/zap v1.27.1 // indirect
 	golang.org/x/arch v0.23.0 // indirect
 	golang.org/x/crypto v0.47.0 // indirect
 	golang.org/x/net v0.49.0 // indirect
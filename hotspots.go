@@ -0,0 +1,249 @@
+//
+// FilePath    : zap-smap\hotspots.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : hotspots 子命令, 将运行时的位置计数与仓库中的日志调用清单对照
+//
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+// runHotspots 读取计数快照(runtime.SiteCounter 的 JSON 输出或包含它的 /debug/vars), 与仓库中的日志调用对照,
+// 输出条数最多的位置、从未输出的位置以及快照中已不存在于源码的位置
+func runHotspots(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("hotspots", flag.ContinueOnError)
+	root := fs.String("path", ".", "源码仓库根目录(与注入时的仓库根一致)")
+	top := fs.Int("top", 20, "输出条数最多的前 N 个位置, 0 表示全部")
+	varName := fs.String("var", "smap_sites", "快照为 /debug/vars 时计数器的 expvar 名称")
+	smap := fs.String("smap", "", "-token 模式的令牌映射文件, 指定后可识别令牌")
+	fs.StringVar(backendsFlg, "backends", *backendsFlg, "自定义日志库 backend 描述文件(与注入时一致)")
+	bindSkipFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("hotspots accepts at most one snapshot file")
+	}
+
+	r := in
+
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	snap, err := readCounterSnapshot(r, *varName)
+	if err != nil {
+		return err
+	}
+
+	var tokens map[string]smaprt.Site
+
+	if *smap != "" {
+		sm, err := loadSourceMap(*smap)
+		if err != nil {
+			return err
+		}
+
+		tokens = sm.Entries
+	}
+
+	if err := initSkipRules(*root); err != nil {
+		return err
+	}
+
+	if err := loadBackends(); err != nil {
+		return err
+	}
+
+	sites, err := collectLogSites(*root, readModulePath(*root))
+	if err != nil {
+		return err
+	}
+
+	printHotspots(out, snap, sites, tokens, *top)
+
+	return nil
+}
+
+// readCounterSnapshot 解析计数快照, 顶层没有 sites 时按 /debug/vars 格式读取 varName 对应的值
+func readCounterSnapshot(r io.Reader, varName string) (smaprt.CounterSnapshot, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return smaprt.CounterSnapshot{}, err
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(b, &top); err != nil {
+		return smaprt.CounterSnapshot{}, fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	if _, ok := top["sites"]; !ok {
+		raw, ok := top[varName]
+		if !ok {
+			return smaprt.CounterSnapshot{}, fmt.Errorf("snapshot has neither \"sites\" nor expvar %q", varName)
+		}
+
+		b = raw
+	}
+
+	var snap smaprt.CounterSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return smaprt.CounterSnapshot{}, fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	return snap, nil
+}
+
+// collectLogSites 遍历仓库, 返回所有日志调用的位置(按文件与行号排序), 跳过规则与注入时一致
+func collectLogSites(root, modulePath string) ([]smaprt.Site, error) {
+	var sites []smaprt.Site
+
+	fSet := token.NewFileSet()
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path != root && shouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if shouldSkipFile(path) {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fSet, path, nil, parser.ParseComments)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warn: parse %s failed: %v\n", path, err)
+			return nil
+		}
+
+		sc := newFileScope(file, fSet)
+		if len(sc.backends) == 0 {
+			return nil
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			ce, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			if lc, ok := matchLogCall(ce, sc); ok {
+				pos, rel, funcName, pkgName := siteInfo(lc, fSet, sc, root)
+				sites = append(sites, smaprt.Site{File: rel, Line: pos.Line, Func: qualifiedFuncName(fileImportPath(pos.Filename, rel, modulePath), funcName, pkgName)})
+			}
+
+			return true
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].File != sites[j].File {
+			return sites[i].File < sites[j].File
+		}
+
+		return sites[i].Line < sites[j].Line
+	})
+
+	return sites, nil
+}
+
+// printHotspots 按 file:line 将快照中的计数归入仓库中的位置并输出三部分结果
+func printHotspots(out io.Writer, snap smaprt.CounterSnapshot, sites []smaprt.Site, tokens map[string]smaprt.Site, top int) {
+	index := make(map[string]int, len(sites))
+	for i, s := range sites {
+		index[fmt.Sprintf("%s:%d", s.File, s.Line)] = i
+	}
+
+	// 同一位置在快照中可能以不同形态出现(例如令牌与 file:line), 合并到源码中的位置
+	fired := make(map[int]bool)
+	emitted := smaprt.CounterSnapshot{Sites: make(map[string]map[string]uint64)}
+	stale := smaprt.CounterSnapshot{Sites: make(map[string]map[string]uint64)}
+
+	for v, levels := range snap.Sites {
+		loc, ok := tokens[v]
+		if !ok {
+			loc, ok = smaprt.ParseSite(v)
+		}
+
+		i, found := index[fmt.Sprintf("%s:%d", loc.File, loc.Line)]
+		if !ok || !found {
+			stale.Sites[v] = levels
+			continue
+		}
+
+		fired[i] = true
+
+		site := sites[i].String()
+		if emitted.Sites[site] == nil {
+			emitted.Sites[site] = make(map[string]uint64)
+		}
+
+		for lvl, n := range levels {
+			emitted.Sites[site][lvl] += n
+		}
+	}
+
+	emitters := emitted.Totals()
+
+	shown := emitters
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+	}
+
+	fmt.Fprintf(out, "[HOTSPOTS] top %d of %d fired sites:\n", len(shown), len(emitters))
+
+	for _, t := range shown {
+		fmt.Fprintln(out, t)
+	}
+
+	fmt.Fprintf(out, "[HOTSPOTS] never fired: %d of %d sites\n", len(sites)-len(fired), len(sites))
+
+	for i, s := range sites {
+		if !fired[i] {
+			fmt.Fprintf(out, "  %s\n", s)
+		}
+	}
+
+	if len(stale.Sites) > 0 {
+		fmt.Fprintf(out, "[HOTSPOTS] not in source: %d\n", len(stale.Sites))
+
+		for _, t := range stale.Totals() {
+			fmt.Fprintln(out, t)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+//
+// FilePath    : zap-smap\hotspots_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : hotspots 子命令单测
+//
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hotspotsSample hotspots 单测使用的源码, 日志调用位于第 6、7、11 行
+const hotspotsSample = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("started", zap.String("fl", "svc/a.go:6"))
+	zap.L().Warn("slow", zap.String("fl", "svc/a.go:7"))
+}
+
+func Stop() {
+	zap.L().Info("stopped", zap.String("fl", "svc/a.go:11"))
+}
+`
+
+// TestRunHotspots 测试按位置汇总计数, 以及输出从未输出与不在源码中的位置
+func TestRunHotspots(t *testing.T) {
+	resetGlobals()
+
+	td := writeTree(t, map[string]string{"go.mod": "module example.com/app\n", "svc/a.go": hotspotsSample})
+
+	// /debug/vars 格式, 同一位置带与不带函数名的计数合并
+	in := strings.NewReader(`{"cmdline":["app"],"smap_sites":{"sites":{` +
+		`"svc/a.go:6":{"info":40},` +
+		`"svc/a.go:6 | example.com/app/svc.Run":{"info":2},` +
+		`"svc/a.go:7":{"warn":3,"error":1},` +
+		`"svc/gone.go:3":{"debug":5}}}}`)
+
+	var out bytes.Buffer
+
+	if err := runHotspots([]string{"-path", td, "-top", "1"}, in, &out); err != nil {
+		t.Fatalf("run hotspots: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"[HOTSPOTS] top 1 of 2 fired sites:",
+		"     42  svc/a.go:6 | example.com/app/svc.Run  info=42",
+		"[HOTSPOTS] never fired: 1 of 3 sites",
+		"  svc/a.go:11 | example.com/app/svc.Stop",
+		"[HOTSPOTS] not in source: 1",
+		"      5  svc/gone.go:3  debug=5",
+	}, "\n") + "\n"
+
+	if got := strings.ReplaceAll(out.String(), "\\", "/"); got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := runHotspots([]string{"-path", td}, strings.NewReader(`{"other":{}}`), &out); err == nil {
+		t.Fatalf("expected error for snapshot without counter")
+	}
+}
+
+// TestRunHotspots_CustomBackend 测试 -backends 描述的日志调用参与对照
+func TestRunHotspots_CustomBackend(t *testing.T) {
+	resetGlobals()
+
+	td := writeTree(t, map[string]string{"go.mod": "module example.com/app\n", "svc/b.go": logxSample, "logx.json": logxBackends})
+	snapshot := `{"sites":{"svc/b.go:6":{"info":3}}}`
+
+	var out bytes.Buffer
+	if err := runHotspots([]string{"-path", td, "-backends", filepath.Join(td, "logx.json")}, strings.NewReader(snapshot), &out); err != nil {
+		t.Fatalf("run hotspots: %v", err)
+	}
+
+	if got := out.String(); !strings.Contains(got, "top 1 of 1 fired sites") || strings.Contains(got, "not in source") {
+		t.Fatalf("expected custom backend site matched, got:\n%s", got)
+	}
+}
@@ -0,0 +1,334 @@
+//
+// FilePath    : zap-smap\ignore.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 跳过规则: 默认排除、-exclude/-include、.gitignore 与 .zap-smapignore
+//
+
+package main
+
+import (
+	"fmt"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jiaopengzi/go-utils"
+)
+
+// 忽略文件名
+const (
+	gitIgnoreFile  = ".gitignore"
+	smapIgnoreFile = ".zap-smapignore"
+)
+
+// defaultExcludes -default-excludes 的默认值, 与 -exclude 语法相同
+const defaultExcludes = "vendor/,.git/,build/,node_modules/,*_gen.go"
+
+// -tests 取值, 决定 _test.go 文件是否处理
+const (
+	testsInclude = "include" // 与其他文件一样处理
+	testsSkip    = "skip"    // 跳过 _test.go
+	testsOnly    = "only"    // 只处理 _test.go
+)
+
+// skipRule 一条 gitignore 风格的规则
+type skipRule struct {
+	base     string // 规则所相对的目录(绝对路径, '/' 分隔)
+	pattern  string // 去掉 '!'、首尾 '/' 后的模式, 支持 *、?、[...] 与跨目录的 **
+	anchored bool   // 模式中含 '/' 时相对 base 匹配完整路径, 否则匹配任意层级的名称
+	dirOnly  bool   // 以 '/' 结尾, 只匹配目录
+	negate   bool   // 以 '!' 开头, 重新包含之前被排除的路径
+}
+
+// skipConfig 一次运行使用的跳过规则
+type skipConfig struct {
+	root     string                // 仓库根目录(-path), 默认排除、-exclude 与 -include 相对该目录
+	top      string                // 读取忽略文件的最上层目录: root 所在 git 仓库的根目录, 不在仓库中时为 root
+	defaults []skipRule            // -default-excludes
+	excludes []skipRule            // -exclude
+	includes []skipRule            // -include, 非空时只处理匹配的文件
+	ignores  map[string][]skipRule // 按目录缓存的忽略文件规则
+}
+
+// skipConf 当前生效的跳过规则, 由 initSkipRules 设置; 未设置时以当前目录为仓库根按参数构建
+var skipConf *skipConfig
+
+// initSkipRules 解析 -default-excludes、-exclude、-include 与 -tests 参数, 相对路径基于 baseDir
+func initSkipRules(baseDir string) error {
+	switch *testsFlg {
+	case testsInclude, testsSkip, testsOnly:
+	default:
+		return fmt.Errorf("unknown -tests %q, expected include, skip or only", *testsFlg)
+	}
+
+	root := absSlash(baseDir)
+
+	c := &skipConfig{
+		root:     root,
+		top:      root,
+		defaults: parseSkipList(*defaultsFlg, root),
+		excludes: parseSkipList(*excludeFlag, root),
+		includes: parseSkipList(*includeFlag, root),
+		ignores:  make(map[string][]skipRule),
+	}
+
+	if repo := findRepoRoot(filepath.FromSlash(root)); repo != "" {
+		c.top = filepath.ToSlash(repo)
+	}
+
+	skipConf = c
+
+	return nil
+}
+
+// currentSkipConfig 返回当前生效的跳过规则
+func currentSkipConfig() *skipConfig {
+	if skipConf == nil {
+		_ = initSkipRules(".")
+	}
+
+	return skipConf
+}
+
+// absSlash 返回 '/' 分隔的绝对路径
+func absSlash(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// parseSkipList 将以逗号分隔的 -exclude/-include 条目解析为规则: 简单名称(例如 node_modules)匹配任意层级,
+// 含 '/' 的条目为相对 root 的路径或 glob, 绝对路径先转换为相对 root 的路径
+func parseSkipList(list, root string) []skipRule {
+	var rules []skipRule
+
+	for p := range strings.SplitSeq(list, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		p = filepath.ToSlash(p)
+
+		if filepath.IsAbs(filepath.FromSlash(p)) {
+			if rel, ok := relUnder(root, absSlash(p)); ok {
+				p = "/" + rel
+			}
+		} else if strings.HasPrefix(p, "./") {
+			p = "/" + pathpkg.Clean(p)
+		}
+
+		if r, ok := parseSkipPattern(p, root); ok {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules
+}
+
+// parseSkipPattern 按 gitignore 语法解析一行规则, 空行与注释返回 false
+func parseSkipPattern(line, base string) (skipRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return skipRule{}, false
+	}
+
+	r := skipRule{base: base}
+
+	if after, ok := strings.CutPrefix(line, "!"); ok {
+		r.negate = true
+		line = after
+	}
+
+	// \# 与 \! 转义开头的字符
+	line = strings.TrimPrefix(line, `\`)
+
+	if trimmed := strings.TrimSuffix(line, "/"); trimmed != line {
+		r.dirOnly = true
+		line = trimmed
+	}
+
+	r.anchored = strings.Contains(line, "/")
+	r.pattern = strings.TrimPrefix(line, "/")
+
+	return r, r.pattern != ""
+}
+
+// readIgnoreFile 读取目录 dir 中的忽略文件, 文件不存在时返回 nil
+func readIgnoreFile(dir, name string) []skipRule {
+	b, err := utils.ReadFile(filepath.Join(filepath.FromSlash(dir), name))
+	if err != nil {
+		return nil
+	}
+
+	var rules []skipRule
+
+	for line := range strings.SplitSeq(string(b), "\n") {
+		if r, ok := parseSkipPattern(line, dir); ok {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules
+}
+
+// relUnder 返回 abs 相对 base 的路径, abs 不在 base 之下时返回 false
+func relUnder(base, abs string) (string, bool) {
+	if abs == base {
+		return "", true
+	}
+
+	if rel, ok := strings.CutPrefix(abs, strings.TrimSuffix(base, "/")+"/"); ok {
+		return rel, true
+	}
+
+	return "", false
+}
+
+// matches 判断规则是否匹配绝对路径 abs
+func (r skipRule) matches(abs string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	rel, ok := relUnder(r.base, abs)
+	if !ok || rel == "" {
+		return false
+	}
+
+	if !r.anchored {
+		rel = pathpkg.Base(rel)
+	}
+
+	return globMatch(strings.Split(r.pattern, "/"), strings.Split(rel, "/"))
+}
+
+// globMatch 逐段匹配路径, 段 ** 匹配零个或多个目录(与 gitignore 一致), 其余段按 path.Match 匹配
+func globMatch(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// 末尾的 ** 只匹配目录之下的内容, 不匹配目录本身
+			if len(pattern) == 1 {
+				return len(name) > 0
+			}
+
+			for i := 0; i <= len(name); i++ {
+				if globMatch(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := pathpkg.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// ignoreRules 返回作用于目录 dir 中路径的忽略文件规则: 自 top 起逐级读取 .gitignore(-gitignore 时)与 .zap-smapignore
+func (c *skipConfig) ignoreRules(dir string) []skipRule {
+	if rules, ok := c.ignores[dir]; ok {
+		return rules
+	}
+
+	var rules []skipRule
+
+	if dir != c.top {
+		if _, ok := relUnder(c.top, dir); ok {
+			rules = append(rules, c.ignoreRules(pathpkg.Dir(dir))...)
+		}
+	}
+
+	if _, ok := relUnder(c.top, dir); ok {
+		if *ignoreFlg {
+			rules = append(rules, readIgnoreFile(dir, gitIgnoreFile)...)
+		}
+
+		rules = append(rules, readIgnoreFile(dir, smapIgnoreFile)...)
+	}
+
+	c.ignores[dir] = rules
+
+	return rules
+}
+
+// excluded 按 默认排除、忽略文件、-exclude 的顺序应用规则, 最后一条匹配的规则决定 abs 是否被排除
+func (c *skipConfig) excluded(abs string, isDir bool) bool {
+	excluded := false
+
+	for _, list := range [][]skipRule{c.defaults, c.ignoreRules(pathpkg.Dir(abs)), c.excludes} {
+		for _, r := range list {
+			if r.matches(abs, isDir) {
+				excluded = !r.negate
+			}
+		}
+	}
+
+	return excluded
+}
+
+// skipped 判断路径是否被排除: 位于 top 之下时其上层目录被排除同样视为排除, 以便单文件模式与目录遍历结果一致
+func (c *skipConfig) skipped(path string, isDir bool) bool {
+	abs := absSlash(path)
+
+	if rel, ok := relUnder(c.top, abs); ok && rel != "" {
+		segs := strings.Split(rel, "/")
+
+		for i := 1; i < len(segs); i++ {
+			if c.excluded(pathpkg.Join(c.top, strings.Join(segs[:i], "/")), true) {
+				return true
+			}
+		}
+	}
+
+	return c.excluded(abs, isDir)
+}
+
+// included 判断文件是否满足 -include: 未指定时全部满足, 否则文件本身或其上层目录须匹配任一规则
+func (c *skipConfig) included(path string) bool {
+	if len(c.includes) == 0 {
+		return true
+	}
+
+	abs := absSlash(path)
+
+	for p, isDir := abs, false; ; p, isDir = pathpkg.Dir(p), true {
+		for _, r := range c.includes {
+			if r.matches(p, isDir) {
+				return true
+			}
+		}
+
+		if _, ok := relUnder(c.root, p); !ok || p == c.root {
+			return false
+		}
+	}
+}
+
+// skipTestFile 按 -tests 策略判断文件是否跳过
+func skipTestFile(path string) bool {
+	isTest := strings.HasSuffix(path, "_test.go")
+
+	switch *testsFlg {
+	case testsSkip:
+		return isTest
+	case testsOnly:
+		return !isTest
+	default:
+		return false
+	}
+}
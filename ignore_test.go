@@ -0,0 +1,122 @@
+//
+// FilePath    : zap-smap\ignore_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 跳过规则单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGlobMatch 测试 glob 的逐段匹配与 **
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.go", "a.go", true},
+		{"*.go", "a/b.go", false},
+		{"**/*_mock.go", "a/b/c_mock.go", true},
+		{"**/*_mock.go", "c_mock.go", true},
+		{"cmd/**/main.go", "cmd/main.go", true},
+		{"cmd/**/main.go", "cmd/a/b/main.go", true},
+		{"legacy/**", "legacy/a.go", true},
+		{"legacy/**", "legacy", false},
+		{"svc/[ab].go", "svc/b.go", true},
+		{"svc/?.go", "svc/ab.go", false},
+	}
+
+	for _, c := range cases {
+		if got := globMatch(strings.Split(c.pattern, "/"), strings.Split(c.name, "/")); got != c.want {
+			t.Fatalf("globMatch(%q, %q) = %v, want %v", c.pattern, c.name, got, c.want)
+		}
+	}
+}
+
+// ignoreFiles 包含忽略文件与各类目录的仓库
+var ignoreFiles = map[string]string{
+	".git/":                  "",
+	".gitignore":             "# generated\n/gen/\n",
+	".zap-smapignore":        "legacy/**\n!legacy/keep.go\n",
+	"internal/svc/a.go":      "package x\n",
+	"internal/svc/a_test.go": "package x\n",
+	"internal/svc/a_mock.go": "package x\n",
+	"gen/b.go":               "package x\n",
+	"legacy/old.go":          "package x\n",
+	"legacy/keep.go":         "package x\n",
+	"vendor/x/x.go":          "package x\n",
+	"pkg/c_gen.go":           "package x\n",
+	"cmd/tools/main.go":      "package x\n",
+}
+
+// skippedFiles 按当前参数初始化跳过规则, 返回 td 下被跳过的文件(相对路径, 逗号分隔)
+func skippedFiles(t *testing.T, td string) string {
+	t.Helper()
+
+	if err := initSkipRules(td); err != nil {
+		t.Fatalf("initSkipRules: %v", err)
+	}
+
+	var skipped []string
+
+	err := filepath.Walk(td, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+
+		if shouldSkipFile(path) {
+			skipped = append(skipped, relPath(path, td))
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+
+	return strings.Join(skipped, ",")
+}
+
+// TestShouldSkipFile_Rules 测试默认排除、忽略文件、-exclude/-include 与 -tests 策略
+func TestShouldSkipFile_Rules(t *testing.T) {
+	td := writeTree(t, ignoreFiles)
+
+	cases := []struct {
+		name    string
+		setup   func()
+		skipped string
+	}{
+		{"defaults", func() {}, "gen/b.go,legacy/old.go,pkg/c_gen.go,vendor/x/x.go"},
+		{"no gitignore", func() { *ignoreFlg = false }, "legacy/old.go,pkg/c_gen.go,vendor/x/x.go"},
+		{"no defaults", func() { *defaultsFlg = "" }, "gen/b.go,legacy/old.go"},
+		{"exclude globs", func() { *excludeFlag = "**/*_mock.go,./cmd/tools" }, "cmd/tools/main.go,gen/b.go,internal/svc/a_mock.go,legacy/old.go,pkg/c_gen.go,vendor/x/x.go"},
+		{"absolute exclude", func() { *excludeFlag = filepath.Join(td, "internal") }, "gen/b.go,internal/svc/a.go,internal/svc/a_mock.go,internal/svc/a_test.go,legacy/old.go,pkg/c_gen.go,vendor/x/x.go"},
+		{"include", func() { *includeFlag = "internal/**"; *testsFlg = testsSkip }, "cmd/tools/main.go,gen/b.go,internal/svc/a_test.go,legacy/keep.go,legacy/old.go,pkg/c_gen.go,vendor/x/x.go"},
+		{"tests only", func() { *testsFlg = testsOnly }, "cmd/tools/main.go,gen/b.go,internal/svc/a.go,internal/svc/a_mock.go,legacy/keep.go,legacy/old.go,pkg/c_gen.go,vendor/x/x.go"},
+	}
+
+	for _, c := range cases {
+		resetGlobals()
+		c.setup()
+
+		if got := skippedFiles(t, td); got != c.skipped {
+			t.Fatalf("%s: skipped %s\nwant %s", c.name, got, c.skipped)
+		}
+	}
+
+	resetGlobals()
+
+	*testsFlg = "none"
+	if err := initSkipRules(td); err == nil {
+		t.Fatalf("expected error for unknown -tests")
+	}
+
+	resetGlobals()
+}
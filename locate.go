@@ -0,0 +1,264 @@
+//
+// FilePath    : zap-smap\locate.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : locate 子命令, 由日志行中的注入字段定位源码并打印上下文
+//
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+// locateOptions locate 子命令的参数
+type locateOptions struct {
+	field      string
+	root       string
+	rev        string
+	modulePath string
+	smap       map[string]smaprt.Site
+	jsonRe     *regexp.Regexp // 匹配 "field": "v", 由 fieldPatterns 生成
+	kvRe       *regexp.Regexp // 匹配 field=v, 由 fieldPatterns 生成
+}
+
+// runLocate 从文件参数或 in 逐行读取 JSON 或 console 格式的日志, 提取注入字段并打印对应的源码片段与所在函数。
+// 无法定位或已过期的位置逐条报告, 存在此类位置时返回错误
+func runLocate(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("locate", flag.ContinueOnError)
+	field := fs.String("field", "fl", "注入字段的键")
+	root := fs.String("path", ".", "源码仓库根目录(与注入时的仓库根一致)")
+	rev := fs.String("rev", "", "按指定的 git revision 读取源码(git show <rev>:<file>), 默认读取工作区")
+	smap := fs.String("smap", "", "-token 模式的令牌映射文件, 指定后可定位令牌")
+	fs.StringVar(backendsFlg, "backends", *backendsFlg, "自定义日志库 backend 描述文件(与注入时一致)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := loadBackends(); err != nil {
+		return err
+	}
+
+	opt := locateOptions{field: *field, root: *root, rev: *rev, modulePath: readModulePath(*root)}
+	opt.jsonRe, opt.kvRe = fieldPatterns(*field)
+
+	if *smap != "" {
+		sm, err := loadSourceMap(*smap)
+		if err != nil {
+			return err
+		}
+
+		opt.smap = sm.Entries
+	}
+
+	seen := make(map[string]bool)
+	failed := 0
+
+	if fs.NArg() == 0 {
+		n, err := locateStream(out, in, opt, seen)
+		if err != nil {
+			return err
+		}
+
+		failed += n
+	}
+
+	for _, name := range fs.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+
+		n, err := locateStream(out, f, opt, seen)
+		f.Close()
+
+		if err != nil {
+			return err
+		}
+
+		failed += n
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d locations could not be resolved", failed, len(seen))
+	}
+
+	return nil
+}
+
+// locateStream 逐行读取日志并定位其中的注入值, 已定位过的值跳过, 返回无法定位的个数
+func locateStream(out io.Writer, r io.Reader, opt locateOptions, seen map[string]bool) (int, error) {
+	failed := 0
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for sc.Scan() {
+		v, ok := extractFieldValue(sc.Text(), opt.field, opt.jsonRe, opt.kvRe)
+		if !ok || seen[v] {
+			continue
+		}
+
+		seen[v] = true
+
+		if err := locateValue(out, v, opt); err != nil {
+			fmt.Fprintf(out, "[LOCATE] %s: %v\n", v, err)
+
+			failed++
+		}
+	}
+
+	return failed, sc.Err()
+}
+
+// fieldPatterns 编译匹配字段 key 的 zap console("key": "v")与 logfmt(key=v)正则, 每次运行只编译一次
+func fieldPatterns(key string) (jsonRe, kvRe *regexp.Regexp) {
+	qk := regexp.QuoteMeta(key)
+
+	jsonRe = regexp.MustCompile(`"` + qk + `"\s*:\s*("(?:[^"\\]|\\.)*")`)
+	kvRe = regexp.MustCompile(`(?:^|\s)` + qk + `=("(?:[^"\\]|\\.)*"|\S+)`)
+
+	return jsonRe, kvRe
+}
+
+// extractFieldValue 从一行日志中提取字段 key 的字符串值, 支持 JSON、zap console("key": "v")与 logfmt(key=v)格式,
+// jsonRe 与 kvRe 由 fieldPatterns(key) 生成
+func extractFieldValue(line, key string, jsonRe, kvRe *regexp.Regexp) (string, bool) {
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err == nil {
+			v, ok := obj[key].(string)
+			return v, ok
+		}
+	}
+
+	if m := jsonRe.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.Unquote(m[1]); err == nil {
+			return v, true
+		}
+	}
+
+	if m := kvRe.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.Unquote(m[1]); err == nil {
+			return v, true
+		}
+
+		return m[1], true
+	}
+
+	return "", false
+}
+
+// locateValue 定位单个注入值并打印源码片段, 无法定位或位置已过期时返回原因
+func locateValue(out io.Writer, v string, opt locateOptions) error {
+	loc, ok := opt.smap[v]
+	if !ok {
+		if loc, ok = smaprt.ParseSite(v); !ok {
+			return fmt.Errorf("unknown location")
+		}
+	}
+
+	src, err := readLocateSource(opt.root, opt.rev, loc.File)
+	if err != nil {
+		return fmt.Errorf("unknown file %s: %w", loc.File, err)
+	}
+
+	funcFull, err := findLogCallAt(src, loc, opt)
+	if err != nil {
+		return err
+	}
+
+	found := smaprt.Site{File: loc.File, Line: loc.Line, Func: funcFull}
+
+	fmt.Fprintf(out, "[LOCATE] %s\n", found)
+	printSnippet(out, strings.Split(string(src), "\n"), loc.File, loc.Line)
+
+	return nil
+}
+
+// readLocateSource 读取工作区或 git revision 中的源码文件
+func readLocateSource(root, rev, file string) ([]byte, error) {
+	if rev == "" {
+		return os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+	}
+
+	// ./ 前缀使路径相对 -C 指定的目录, root 为仓库子目录时同样有效
+	cmd := exec.Command("git", "-C", root, "show", rev+":./"+file)
+
+	b, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("git show %s:%s: %s", rev, file, strings.TrimSpace(string(ee.Stderr)))
+		}
+
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// findLogCallAt 在源码中查找位于 loc.Line 的日志调用, 返回其所在函数的完整名称。
+// 该行没有日志调用或所在函数与 loc.Func 不一致时视为位置已过期
+func findLogCallAt(src []byte, loc smaprt.Site, opt locateOptions) (string, error) {
+	fSet := token.NewFileSet()
+
+	file, err := parser.ParseFile(fSet, loc.File, src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", loc.File, err)
+	}
+
+	sc := newFileScope(file, fSet)
+
+	found := false
+	funcFull := ""
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+
+		lc, ok := matchLogCall(ce, sc)
+		if !ok {
+			return true
+		}
+
+		pos, _, funcName, pkgName := siteInfo(lc, fSet, sc, opt.root)
+		if pos.Line != loc.Line {
+			return true
+		}
+
+		found = true
+		path := filepath.Join(opt.root, filepath.FromSlash(loc.File))
+		funcFull = qualifiedFuncName(fileImportPath(path, loc.File, opt.modulePath), funcName, pkgName)
+
+		return false
+	})
+
+	if !found {
+		return "", fmt.Errorf("stale location, no log call at %s:%d", loc.File, loc.Line)
+	}
+
+	if loc.Func != "" && loc.Func != funcFull {
+		return "", fmt.Errorf("stale location, %s:%d is in %s, not %s", loc.File, loc.Line, funcFull, loc.Func)
+	}
+
+	return funcFull, nil
+}
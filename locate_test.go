@@ -0,0 +1,182 @@
+//
+// FilePath    : zap-smap\locate_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : locate 子命令单测
+//
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// locateSample locate 单测使用的源码, Info 调用位于第 6 行
+const locateSample = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("started", zap.String("fl", "svc/a.go:6 | example.com/app/svc.Run"))
+}
+`
+
+// logxSample 使用 logxBackends 描述的日志库的源码, Infof 调用位于第 6 行
+const logxSample = `package svc
+
+import "example.com/infra/logx"
+
+func Run(ctx any) {
+	logx.Get(ctx).Infof(ctx, "started", logx.Str("fl", "svc/b.go:6"))
+}
+`
+
+// locateFiles 包含 go.mod 与 svc/a.go 的仓库
+var locateFiles = map[string]string{
+	"go.mod":   "module example.com/app\n",
+	"svc/a.go": locateSample,
+}
+
+// TestRunLocate_LogFormats 测试从 JSON、console 与 logfmt 日志中提取字段并打印源码片段, 无法定位的位置逐条报告
+func TestRunLocate_LogFormats(t *testing.T) {
+	resetGlobals()
+
+	td := writeTree(t, locateFiles)
+
+	in := strings.NewReader(strings.Join([]string{
+		`{"level":"info","msg":"started","fl":"svc/a.go:6 | example.com/app/svc.Run"}`,
+		"2026-01-01T00:00:00Z\tINFO\tstarted\t{\"fl\": \"svc/a.go:6\"}",
+		`time=2026-01-01 level=INFO msg=started fl=svc/a.go:6`,
+		`{"level":"info","msg":"no location"}`,
+		`{"level":"info","fl":"svc/a.go:3"}`,
+		`{"level":"info","fl":"svc/a.go:6 | example.com/app/svc.Stop"}`,
+		`{"level":"info","fl":"svc/gone.go:1"}`,
+		`{"level":"info","fl":"garbage"}`,
+	}, "\n"))
+
+	var out bytes.Buffer
+
+	err := runLocate([]string{"-path", td}, in, &out)
+	if err == nil || !strings.Contains(err.Error(), "4 of 6 locations could not be resolved") {
+		t.Fatalf("expected unresolved locations error, got %v", err)
+	}
+
+	s := strings.ReplaceAll(out.String(), "\\", "/")
+
+	wants := []string{
+		"[LOCATE] svc/a.go:6 | example.com/app/svc.Run\n--- snippet around L6 (svc/a.go) ---\n",
+		`    6: 	zap.L().Info("started"`,
+		"[LOCATE] svc/a.go:3: stale location, no log call at svc/a.go:3",
+		"[LOCATE] svc/a.go:6 | example.com/app/svc.Stop: stale location, svc/a.go:6 is in example.com/app/svc.Run, not example.com/app/svc.Stop",
+		"[LOCATE] svc/gone.go:1: unknown file svc/gone.go",
+		"[LOCATE] garbage: unknown location",
+	}
+	for _, w := range wants {
+		if !strings.Contains(s, w) {
+			t.Fatalf("expected %q, got:\n%s", w, s)
+		}
+	}
+
+	if n := strings.Count(s, "--- snippet around L6"); n != 2 {
+		t.Fatalf("expected 2 snippets (plain and with func), got %d:\n%s", n, s)
+	}
+}
+
+// TestRunLocate_GitRevision 测试 -rev 从指定 git revision 读取源码
+func TestRunLocate_GitRevision(t *testing.T) {
+	resetGlobals()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	td := writeTree(t, locateFiles)
+
+	git := func(args ...string) {
+		t.Helper()
+
+		cmd := exec.Command("git", append([]string{"-C", td, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if b, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, b)
+		}
+	}
+
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	// 工作区中的调用下移一行, 第 6 行不再是日志调用
+	writeFile(t, filepath.Join(td, "svc"), "a.go", strings.Replace(locateSample, "func Run() {", "func Run() {\n\t_ = 0", 1))
+
+	line := `{"fl":"svc/a.go:6"}`
+
+	var out bytes.Buffer
+	if err := runLocate([]string{"-path", td}, strings.NewReader(line), &out); err == nil {
+		t.Fatalf("expected stale location in working tree, got:\n%s", out.String())
+	}
+
+	out.Reset()
+
+	if err := runLocate([]string{"-path", td, "-rev", "HEAD"}, strings.NewReader(line), &out); err != nil {
+		t.Fatalf("locate at HEAD: %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "[LOCATE] svc/a.go:6 | example.com/app/svc.Run") {
+		t.Fatalf("expected location resolved at HEAD, got:\n%s", out.String())
+	}
+}
+
+// TestRunLocate_GitRevisionSubdir 测试 -path 为仓库子目录时 -rev 按该目录读取源码
+func TestRunLocate_GitRevisionSubdir(t *testing.T) {
+	resetGlobals()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	td := writeTree(t, map[string]string{"app/go.mod": locateFiles["go.mod"], "app/svc/a.go": locateSample})
+
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-q", "-m", "init"}} {
+		cmd := exec.Command("git", append([]string{"-C", td, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if b, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, b)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runLocate([]string{"-path", filepath.Join(td, "app"), "-rev", "HEAD"}, strings.NewReader(`{"fl":"svc/a.go:6"}`), &out); err != nil {
+		t.Fatalf("locate at HEAD: %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "[LOCATE] svc/a.go:6 | example.com/app/svc.Run") {
+		t.Fatalf("expected location resolved in subdirectory, got:\n%s", out.String())
+	}
+}
+
+// TestRunLocate_CustomBackend 测试 -backends 描述的日志调用可以被定位
+func TestRunLocate_CustomBackend(t *testing.T) {
+	resetGlobals()
+
+	td := writeTree(t, map[string]string{"go.mod": locateFiles["go.mod"], "svc/b.go": logxSample, "logx.json": logxBackends})
+	line := `{"fl":"svc/b.go:6"}`
+
+	var out bytes.Buffer
+	if err := runLocate([]string{"-path", td}, strings.NewReader(line), &out); err == nil {
+		t.Fatalf("expected unknown logger without -backends, got:\n%s", out.String())
+	}
+
+	out.Reset()
+
+	if err := runLocate([]string{"-path", td, "-backends", filepath.Join(td, "logx.json")}, strings.NewReader(line), &out); err != nil {
+		t.Fatalf("locate with -backends: %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "[LOCATE] svc/b.go:6 | example.com/app/svc.Run") {
+		t.Fatalf("expected custom backend call located, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,487 @@
+//
+// FilePath    : zap-smap\locs.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -locs 模式的位置常量分配与 zz_smap_locs.go 生成
+//
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// locIdentPrefix 位置常量名前缀
+	locIdentPrefix = "smapLoc_"
+
+	// locIdentHashLen 位置常量名中哈希部分的长度(十六进制字符数)
+	locIdentHashLen = 8
+
+	// locsFileName 位置常量表文件名
+	locsFileName = "zz_smap_locs.go"
+
+	// locsTestFileName 外部测试包(package xxx_test)的位置常量表文件名
+	locsTestFileName = "zz_smap_locs_test.go"
+)
+
+// locTables -locs 模式下本次运行涉及的包的位置常量表, 键为 目录 + 包名; 非 -locs 模式为 nil
+var locTables map[string]*locTable
+
+// locTable 一个包的位置常量表
+type locTable struct {
+	dir       string
+	pkg       string
+	path      string            // 表文件路径
+	existing  map[string]string // 表文件中已有的 常量名 → 位置字符串, 表文件不存在时为 nil
+	entries   map[string]string // 从源码收集的 常量名 → 位置字符串
+	owners    map[string]string // 常量名 → 引用它的文件(相对路径)
+	processed map[string]bool   // 已收集过引用的文件
+	conflicts []string          // 同一常量被多个调用引用的问题描述
+}
+
+// initLocsMode 根据 -locs 参数初始化位置常量表集合
+func initLocsMode() {
+	locTables = nil
+
+	if *locsFlg {
+		locTables = make(map[string]*locTable)
+	}
+}
+
+// isLocIdent 判断表达式是否为位置常量引用
+func isLocIdent(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+
+	return ok && strings.HasPrefix(id.Name, locIdentPrefix)
+}
+
+// isLocsFile 判断文件是否为生成的位置常量表
+func isLocsFile(path string) bool {
+	base := filepath.Base(path)
+
+	return base == locsFileName || base == locsTestFileName
+}
+
+// locTableFor 返回 path 所在目录中包 pkg 的位置常量表, 首次访问时读取已有的表文件
+func locTableFor(path, pkg string) *locTable {
+	dir := filepath.Dir(path)
+	key := dir + "\x00" + pkg
+
+	if lt, ok := locTables[key]; ok {
+		return lt
+	}
+
+	name := locsFileName
+	if strings.HasSuffix(pkg, "_test") {
+		name = locsTestFileName
+	}
+
+	lt := &locTable{
+		dir:       dir,
+		pkg:       pkg,
+		path:      filepath.Join(dir, name),
+		entries:   make(map[string]string),
+		owners:    make(map[string]string),
+		processed: make(map[string]bool),
+	}
+	lt.existing = readLocTable(lt.path)
+	locTables[key] = lt
+
+	return lt
+}
+
+// readLocTable 读取表文件中的字符串常量, 文件不存在或无法解析时返回 nil
+func readLocTable(path string) map[string]string {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+	if err != nil {
+		return nil
+	}
+
+	consts := make(map[string]string)
+
+	for _, d := range file.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			continue
+		}
+
+		for _, spec := range gd.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+
+			for i, name := range vs.Names {
+				if i < len(vs.Values) {
+					consts[name.Name] = parseLitKey(vs.Values[i])
+				}
+			}
+		}
+	}
+
+	return consts
+}
+
+// assignLocIdents 为文件中每个注入目标确定应引用的位置常量名:
+// 沿用调用处已引用且未被其他调用占用的常量, 其余按 文件|函数|函数内序号 生成新的常量名。
+// 常量名与行号无关, 行号变化时只需重新生成常量表
+func assignLocIdents(sc *fileScope, fSet *token.FileSet, path, baseDir string) map[*ast.CallExpr]string {
+	lt := locTableFor(path, sc.file.Name.Name)
+	rel := relPath(path, baseDir)
+
+	type pendingLoc struct {
+		ce   *ast.CallExpr
+		seed string
+	}
+
+	var pending []pendingLoc
+
+	idents := make(map[*ast.CallExpr]string)
+	used := make(map[string]bool)
+	ordinals := make(map[string]int)
+
+	ast.Inspect(sc.file, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		lc, ok := matchLogCall(ce, sc)
+		if !ok || sc.policyFor(lc.level) != policyInject {
+			return true
+		}
+
+		_, _, funcName, _ := siteInfo(lc, fSet, sc, baseDir)
+		seed := fmt.Sprintf("%s|%s|%d", rel, funcName, ordinals[funcName])
+		ordinals[funcName]++
+
+		if id := currentLocIdent(lc); id != "" && !used[id] && !lt.ownedElsewhere(id, rel) {
+			used[id] = true
+			idents[ce] = id
+
+			return true
+		}
+
+		pending = append(pending, pendingLoc{ce: ce, seed: seed})
+
+		return true
+	})
+
+	// 先保留已有引用, 再分配新常量名, 避免新常量名占用后文已引用的常量
+	for _, p := range pending {
+		id := lt.newIdent(p.seed, used)
+		used[id] = true
+		idents[p.ce] = id
+	}
+
+	return idents
+}
+
+// currentLocIdent 返回调用处 -field 字段当前引用的位置常量名, 未引用时返回空串
+func currentLocIdent(lc logCall) string {
+	var v ast.Expr
+
+	if lc.isEllipsis() {
+		if _, fieldCall, _ := findEllipsisFieldCall(lc.b, lc.ce.Args[len(lc.ce.Args)-1], *fieldFlg); fieldCall != nil && len(fieldCall.Args) > 1 {
+			v = fieldCall.Args[1]
+		}
+	} else if f, ok := findField(lc, *fieldFlg); ok {
+		v = f.value
+	}
+
+	if id, ok := v.(*ast.Ident); ok && isLocIdent(id) {
+		return id.Name
+	}
+
+	return ""
+}
+
+// ownedElsewhere 判断常量 id 是否已被 rel 以外的文件引用
+func (lt *locTable) ownedElsewhere(id, rel string) bool {
+	owner, ok := lt.owners[id]
+
+	return ok && owner != rel
+}
+
+// newIdent 由 seed 生成未被占用的常量名, 冲突时在 seed 后追加序号重新计算
+func (lt *locTable) newIdent(seed string, used map[string]bool) string {
+	for n := 0; ; n++ {
+		sum := sha256.Sum256(fmt.Appendf(nil, "%s#%d", seed, n))
+		id := locIdentPrefix + hex.EncodeToString(sum[:])[:locIdentHashLen]
+
+		if _, taken := lt.existing[id]; !taken && !used[id] && lt.owners[id] == "" {
+			return id
+		}
+	}
+}
+
+// record 登记常量 id 对应的位置字符串, 同一常量被不同位置引用时记录冲突
+func (lt *locTable) record(id, value, rel string, line int) {
+	if prev, ok := lt.entries[id]; ok && prev != value {
+		lt.conflicts = append(lt.conflicts, fmt.Sprintf("%s:%d: %s is already referenced by another call in %s", rel, line, id, lt.owners[id]))
+		return
+	}
+
+	lt.entries[id] = value
+	lt.owners[id] = rel
+}
+
+// recordLocs 收集文件最终内容中日志调用引用的位置常量, 以调用所在位置计算常量值。
+// modified 为 true 时使用生成内容 out, 否则读取磁盘上的文件
+func recordLocs(path string, modified bool, out string, modulePath, baseDir string) {
+	if locTables == nil {
+		return
+	}
+
+	src, err := finalSource(path, modified, out)
+	if err != nil {
+		return
+	}
+
+	fSet := token.NewFileSet()
+
+	file, err := parser.ParseFile(fSet, path, src, parser.ParseComments)
+	if err != nil {
+		return
+	}
+
+	sc := newFileScope(file, fSet)
+	if len(sc.backends) == 0 {
+		return
+	}
+
+	lt := locTableFor(path, file.Name.Name)
+	lt.processed[path] = true
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		lc, ok := matchLogCall(ce, sc)
+		if !ok {
+			return true
+		}
+
+		pos, rel, funcName, pkgName := siteInfo(lc, fSet, sc, baseDir)
+		value := buildInjectedValue(rel, pos, funcName, pkgName, modulePath)
+
+		ast.Inspect(ce, func(m ast.Node) bool {
+			// 嵌套的日志调用由外层遍历单独处理
+			if inner, ok := m.(*ast.CallExpr); ok && inner != ce {
+				if _, ok := matchLogCall(inner, sc); ok {
+					return false
+				}
+			}
+
+			if id, ok := m.(*ast.Ident); ok && isLocIdent(id) {
+				lt.record(id.Name, value, rel, pos.Line)
+			}
+
+			return true
+		})
+
+		return true
+	})
+}
+
+// completeLocTables 收集表所在目录中本次未处理的文件(例如被 -exclude 排除的文件)中的引用, 使常量表覆盖整个包
+func completeLocTables(modulePath, baseDir string) {
+	for _, lt := range sortedLocTables() {
+		entries, err := os.ReadDir(lt.dir)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range entries {
+			path := filepath.Join(lt.dir, e.Name())
+			if e.IsDir() || !strings.HasSuffix(path, ".go") || isLocsFile(path) || lt.processed[path] {
+				continue
+			}
+
+			recordLocs(path, false, "", modulePath, baseDir)
+		}
+	}
+}
+
+// sortedLocTables 按表文件路径排序返回所有位置常量表
+func sortedLocTables() []*locTable {
+	tables := make([]*locTable, 0, len(locTables))
+	for _, lt := range locTables {
+		tables = append(tables, lt)
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].path < tables[j].path
+	})
+
+	return tables
+}
+
+// renderLocTable 生成位置常量表源码, 常量按名称排序, 行号变化时只有常量值发生变化
+func renderLocTable(pkg string, entries map[string]string) []byte {
+	names := make([]string, 0, len(entries))
+	for id := range entries {
+		names = append(names, id)
+	}
+
+	sort.Strings(names)
+
+	var sb strings.Builder
+
+	sb.WriteString("// Code generated by zap-smap -locs; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&sb, "package %s\n\nconst (\n", pkg)
+
+	for _, id := range names {
+		fmt.Fprintf(&sb, "\t%s = %s\n", id, strconv.Quote(entries[id]))
+	}
+
+	sb.WriteString(")\n")
+
+	out, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return []byte(sb.String())
+	}
+
+	return out
+}
+
+// writeLocTables 重新生成本次运行涉及的包的位置常量表, 仅在内容变化时输出 [LOCS]; 包内不再有引用时删除表文件。
+// dry-run 下只输出不写回
+func writeLocTables(modulePath, baseDir string) error {
+	if locTables == nil {
+		return nil
+	}
+
+	completeLocTables(modulePath, baseDir)
+
+	for _, lt := range sortedLocTables() {
+		for _, c := range lt.conflicts {
+			fmt.Fprintf(os.Stderr, "warn: %s\n", c)
+		}
+
+		rel := relPath(lt.path, baseDir)
+
+		if len(lt.entries) == 0 {
+			if _, err := os.Stat(lt.path); err != nil {
+				continue
+			}
+
+			fmt.Printf("[LOCS] %s: removed\n", rel)
+
+			if *writeFlg {
+				if err := os.Remove(lt.path); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		data := renderLocTable(lt.pkg, lt.entries)
+		if old, err := os.ReadFile(lt.path); err == nil && bytes.Equal(old, data) {
+			continue
+		}
+
+		fmt.Printf("[LOCS] %s: %d entries\n", rel, len(lt.entries))
+
+		if *writeFlg {
+			if err := os.WriteFile(lt.path, data, 0600); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyLocTable 校验表文件与源码中的引用一致, 返回问题描述列表
+func verifyLocTable(lt *locTable, rel string) []string {
+	issues := append([]string(nil), lt.conflicts...)
+
+	if len(lt.entries) == 0 {
+		if _, err := os.Stat(lt.path); err == nil {
+			issues = append(issues, fmt.Sprintf("%s: stale table without references", rel))
+		}
+
+		return issues
+	}
+
+	if lt.existing == nil {
+		return append(issues, fmt.Sprintf("%s: missing table for %d references", rel, len(lt.entries)))
+	}
+
+	for id, v := range lt.entries {
+		actual, ok := lt.existing[id]
+
+		switch {
+		case !ok:
+			issues = append(issues, fmt.Sprintf("%s: missing const %s, expected='%s'", rel, id, v))
+		case actual != v:
+			issues = append(issues, fmt.Sprintf("%s: const %s mismatch actual='%s' expected='%s'", rel, id, actual, v))
+		}
+	}
+
+	for id := range lt.existing {
+		if _, ok := lt.entries[id]; !ok {
+			issues = append(issues, fmt.Sprintf("%s: stale const %s", rel, id))
+		}
+	}
+
+	sort.Strings(issues)
+
+	return issues
+}
+
+// reportLocTables 校验 -locs 模式的位置常量表并打印问题, 返回问题数与有问题的表文件
+func reportLocTables(modulePath, baseDir string) (int, []string) {
+	if locTables == nil {
+		return 0, nil
+	}
+
+	completeLocTables(modulePath, baseDir)
+
+	count := 0
+
+	var files []string
+
+	for _, lt := range sortedLocTables() {
+		rel := relPath(lt.path, baseDir)
+
+		issues := verifyLocTable(lt, rel)
+		if len(issues) == 0 {
+			continue
+		}
+
+		fmt.Printf("[VERIFY] %s: locs=%d\n", rel, len(issues))
+
+		for _, it := range issues {
+			fmt.Println(it)
+		}
+
+		count += len(issues)
+		files = append(files, rel)
+	}
+
+	return count, files
+}
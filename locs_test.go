@@ -0,0 +1,187 @@
+//
+// FilePath    : zap-smap\locs_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -locs 位置常量表单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// locRefPattern 匹配调用处引用的位置常量
+var locRefPattern = regexp.MustCompile(`smapLoc_[0-9a-f]{8}`)
+
+// readTestFile 读取 dir 下的文件内容
+func readTestFile(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	return string(b)
+}
+
+// TestMain_Locs_LineShiftOnlyChangesTable 测试调用处引用位置常量, 行号变化时只重新生成 zz_smap_locs.go
+func TestMain_Locs_LineShiftOnlyChangesTable(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "a.go", `package app
+
+import (
+	"log/slog"
+
+	"go.uber.org/zap"
+)
+
+func Foo(fields []zap.Field) {
+	zap.L().Info("hello")
+	zap.L().Error("failed", fields...)
+	slog.Warn("w", "k", 1)
+}
+`)
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*locsFlg = true
+	os.Args = []string{"cmd"}
+
+	out := captureOutput(func() { main() })
+	if !strings.Contains(out, "[LOCS] zz_smap_locs.go: 3 entries") {
+		t.Fatalf("expected table generated, got:\n%s", out)
+	}
+
+	src := readTestFile(t, td, "a.go")
+
+	refs := locRefPattern.FindAllString(src, -1)
+	if len(refs) != 3 {
+		t.Fatalf("expected 3 const references, got:\n%s", src)
+	}
+
+	wants := []string{
+		`zap.L().Info("hello", zap.String("fl", ` + refs[0] + `))`,
+		`zap.L().Error("failed", append([]zap.Field{zap.String("fl", ` + refs[1] + `)}, fields...)...)`,
+		`slog.Warn("w", slog.String("fl", ` + refs[2] + `), "k", 1)`,
+	}
+	for _, w := range wants {
+		if !strings.Contains(src, w) {
+			t.Fatalf("expected %s, got:\n%s", w, src)
+		}
+	}
+
+	table := readTestFile(t, td, locsFileName)
+	for i, line := range []string{"a.go:10", "a.go:11", "a.go:12"} {
+		if !strings.Contains(table, refs[i]+` = "`+line+`"`) {
+			t.Fatalf("expected %s = %q in table, got:\n%s", refs[i], line, table)
+		}
+	}
+
+	if !strings.HasPrefix(table, "// Code generated by zap-smap -locs; DO NOT EDIT.\n\npackage app\n") {
+		t.Fatalf("unexpected table header:\n%s", table)
+	}
+
+	// 在文件顶部插入一行后, verify 报告常量表过期
+	writeFile(t, td, "a.go", "// Package app.\n"+src)
+
+	*writeFlg = false
+	*verifyFlg = true
+
+	out = captureOutput(func() { main() })
+	if !strings.Contains(out, "locs: 3") || !strings.Contains(out, "const "+refs[0]+" mismatch actual='a.go:10' expected='a.go:11'") {
+		t.Fatalf("expected stale table reported, got:\n%s", out)
+	}
+
+	// 重新生成时调用处不变, 只有常量表变化
+	*verifyFlg = false
+	*writeFlg = true
+
+	out = captureOutput(func() { main() })
+	if strings.Contains(out, "[PATCH]") || !strings.Contains(out, "[LOCS] zz_smap_locs.go") {
+		t.Fatalf("expected only the table rewritten, got:\n%s", out)
+	}
+
+	if !strings.Contains(readTestFile(t, td, locsFileName), refs[0]+` = "a.go:11"`) {
+		t.Fatalf("expected table value updated, got:\n%s", readTestFile(t, td, locsFileName))
+	}
+
+	*writeFlg = false
+	*verifyFlg = true
+
+	out = captureOutput(func() { main() })
+	if !strings.Contains(out, "locs: 0") || !strings.Contains(out, "All injections look correct.") {
+		t.Fatalf("expected clean verify, got:\n%s", out)
+	}
+}
+
+// TestMain_Locs_DuplicateReferenceAndDelete 测试复制粘贴产生的重复引用被重新分配, 以及 -del 同时删除引用与常量表
+func TestMain_Locs_DuplicateReferenceAndDelete(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "a.go", `package app
+
+import "go.uber.org/zap"
+
+func Foo() {
+	zap.L().Info("a", zap.String("fl", smapLoc_00000000))
+	zap.L().Info("b", zap.String("fl", smapLoc_00000000))
+}
+`)
+	writeFile(t, td, locsFileName, "package app\n\nconst smapLoc_00000000 = \"a.go:6\"\n")
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*locsFlg = true
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	src := readTestFile(t, td, "a.go")
+	if !strings.Contains(src, `zap.L().Info("a", zap.String("fl", smapLoc_00000000))`) {
+		t.Fatalf("expected first reference kept, got:\n%s", src)
+	}
+
+	refs := locRefPattern.FindAllString(src, -1)
+	if len(refs) != 2 || refs[1] == refs[0] {
+		t.Fatalf("expected duplicate reference reassigned, got:\n%s", src)
+	}
+
+	table := readTestFile(t, td, locsFileName)
+	if !strings.Contains(table, `smapLoc_00000000 = "a.go:6"`) || !strings.Contains(table, refs[1]+` = "a.go:7"`) {
+		t.Fatalf("unexpected table:\n%s", table)
+	}
+
+	resetGlobals()
+
+	*pathFlag = td
+	*writeFlg = true
+	*delFlg = "fl"
+	*locsFlg = true
+
+	out := captureOutput(func() { main() })
+	if !strings.Contains(out, "[LOCS] zz_smap_locs.go: removed") {
+		t.Fatalf("expected table removed, got:\n%s", out)
+	}
+
+	if src := readTestFile(t, td, "a.go"); strings.Contains(src, "smapLoc_") {
+		t.Fatalf("expected references removed, got:\n%s", src)
+	}
+
+	if _, err := os.Stat(filepath.Join(td, locsFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected %s deleted, stat err: %v", locsFileName, err)
+	}
+}
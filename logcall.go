@@ -0,0 +1,435 @@
+//
+// FilePath    : zap-smap\logcall.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 识别日志调用的各种形式以及派生自调用链起点的 logger 变量
+//
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// logCall 描述一次被识别为日志的调用
+type logCall struct {
+	ce         *ast.CallExpr // 承载字段参数的调用, Check 形式下为 Write 调用
+	b          *backend      // 调用所属的日志库
+	method     string        // 日志方法名, 例如 Info、Log、Write
+	level      string        // 用于 -level-policy 的级别名称
+	fieldStart int           // 字段参数在 ce.Args 中的起始索引
+	site       token.Pos     // 计算 file:line 所用的位置, Check 形式下为 Check 调用的左括号
+	sliceType  string        // ellipsis 调用包裹注入字段时使用的切片元素类型
+	kvPairs    bool          // 字段参数是否可能为 "key", value 键值对
+	chain      *eventChain   // 链式 backend 的事件调用链, 此时 ce 为结束调用
+}
+
+// eventChain 描述 zerolog 形式的事件调用链: <logger>.Info().Str(k, v)...Msg(msg)
+type eventChain struct {
+	level *ast.CallExpr   // 创建事件的级别调用, 例如 log.Info()
+	links []*ast.CallExpr // 级别调用与结束调用之间的方法调用(按源码顺序), 即字段列表
+}
+
+// isEllipsis 判断字段是否以 fields... 形式展开传入
+func (lc logCall) isEllipsis() bool {
+	return lc.chain == nil && lc.ce.Ellipsis.IsValid()
+}
+
+// anchor 返回新建字段节点使用的位置: 参数形式为调用的左括号, 调用链形式为级别调用的右括号
+func (lc logCall) anchor() token.Pos {
+	if lc.chain != nil {
+		return lc.chain.level.Rparen
+	}
+
+	return lc.ce.Lparen
+}
+
+// fields 返回字段列表的副本: 参数形式为 ce.Args[fieldStart:], 调用链形式为级别调用与结束调用之间的方法调用
+func (lc logCall) fields() []ast.Expr {
+	if lc.chain == nil {
+		return append([]ast.Expr(nil), lc.ce.Args[lc.fieldStart:]...)
+	}
+
+	exprs := make([]ast.Expr, 0, len(lc.chain.links))
+	for _, l := range lc.chain.links {
+		exprs = append(exprs, l)
+	}
+
+	return exprs
+}
+
+// setFields 用 exprs 替换字段列表, 调用链形式下按顺序重新串联各方法调用的接收者
+func (lc logCall) setFields(exprs []ast.Expr) {
+	if lc.chain == nil {
+		lc.ce.Args = append(append([]ast.Expr(nil), lc.ce.Args[:lc.fieldStart]...), exprs...)
+		return
+	}
+
+	var prev ast.Expr = lc.chain.level
+
+	links := make([]*ast.CallExpr, 0, len(exprs))
+
+	for _, e := range exprs {
+		link, ok := e.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+
+		if sel, ok := link.Fun.(*ast.SelectorExpr); ok {
+			sel.X = prev
+		}
+
+		links = append(links, link)
+		prev = link
+	}
+
+	lc.ce.Fun.(*ast.SelectorExpr).X = prev
+	lc.chain.links = links
+}
+
+// matchLogCall 依次使用文件导入的 backend 判断 ce 是否为日志调用
+func matchLogCall(ce *ast.CallExpr, sc *fileScope) (logCall, bool) {
+	for _, b := range sc.backends {
+		if lc, ok := b.matchCall(ce, sc); ok {
+			return lc, true
+		}
+	}
+
+	return logCall{}, false
+}
+
+// matchCall 判断 ce 是否为 b 的日志调用, 支持以下形式:
+//   - zap.L().Info(msg, fields...)、slog.Info(msg, args...) 等级别方法
+//   - zap.L().Log(lvl, msg, fields...)、logger.InfoContext(ctx, msg, args...)、logger.LogAttrs(ctx, lvl, msg, attrs...) 等 msg 不在首位的方法
+//   - zap.L().Check(lvl, msg).Write(fields...) 以及 ce := zap.L().Check(lvl, msg); ce.Write(fields...)
+func (b *backend) matchCall(ce *ast.CallExpr, sc *fileScope) (logCall, bool) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return logCall{}, false
+	}
+
+	method := sel.Sel.Name
+
+	if b.isChainStyle() {
+		return b.matchChain(ce, sel, sc)
+	}
+
+	if spec, ok := b.methods[method]; ok {
+		if len(ce.Args) <= spec.msgIndex || !b.isReceiver(sel.X, sc) {
+			return logCall{}, false
+		}
+
+		level := spec.level
+		if level == "" {
+			level = b.levelFromExpr(ce.Args[spec.levelArg])
+		}
+
+		return logCall{
+			ce:         ce,
+			b:          b,
+			method:     method,
+			level:      level,
+			fieldStart: spec.msgIndex + 1,
+			site:       ce.Lparen,
+			sliceType:  spec.sliceType,
+			kvPairs:    b.kvPairs && !spec.attrsOnly,
+		}, true
+	}
+
+	if b.checkWrite && method == zapMethodWrite {
+		check := b.resolveCheckCall(sel.X, sc)
+		if check == nil {
+			return logCall{}, false
+		}
+
+		return logCall{
+			ce:        ce,
+			b:         b,
+			method:    method,
+			level:     b.levelFromExpr(check.Args[0]),
+			site:      check.Lparen,
+			sliceType: b.sliceType,
+		}, true
+	}
+
+	return logCall{}, false
+}
+
+// matchChain 判断 ce 是否为 b 的事件调用链结束调用, 例如 log.Info().Str("k", v).Msg("...")。
+// 从结束调用的接收者开始向内查找级别调用, 途经的方法调用即为字段列表
+func (b *backend) matchChain(ce *ast.CallExpr, sel *ast.SelectorExpr, sc *fileScope) (logCall, bool) {
+	if !b.chainEnds[sel.Sel.Name] {
+		return logCall{}, false
+	}
+
+	var links []*ast.CallExpr
+
+	for x := sel.X; ; {
+		call, ok := x.(*ast.CallExpr)
+		if !ok {
+			return logCall{}, false
+		}
+
+		csel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return logCall{}, false
+		}
+
+		if spec, ok := b.methods[csel.Sel.Name]; ok && len(call.Args) == 0 && b.isReceiver(csel.X, sc) {
+			// links 由外向内收集, 反转为源码顺序
+			for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+				links[i], links[j] = links[j], links[i]
+			}
+
+			return logCall{
+				ce:     ce,
+				b:      b,
+				method: csel.Sel.Name,
+				level:  spec.level,
+				site:   call.Lparen,
+				chain:  &eventChain{level: call, links: links},
+			}, true
+		}
+
+		links = append(links, call)
+		x = csel.X
+	}
+}
+
+// isReceiver 判断日志方法的接收者是否属于 b: 包级函数形式(slog.Info)或 logger 调用链
+func (b *backend) isReceiver(x ast.Expr, sc *fileScope) bool {
+	if id, ok := x.(*ast.Ident); ok && b.pkgFuncs && id.Name == b.ident && id.Obj == nil {
+		return true
+	}
+
+	return b.isChain(x, sc)
+}
+
+// resolveCheckCall 返回 Write 调用的接收者所对应的 zap.L().Check(lvl, msg) 调用, 未找到返回 nil。
+// 接收者可以是 Check 调用本身, 也可以是由 Check 调用赋值的局部变量(依赖 parser 的标识符解析)
+func (b *backend) resolveCheckCall(recv ast.Expr, sc *fileScope) *ast.CallExpr {
+	switch v := recv.(type) {
+	case *ast.CallExpr:
+		if b.isCheckCall(v, sc) {
+			return v
+		}
+	case *ast.Ident:
+		if rhs := identInitExpr(v); rhs != nil {
+			if call, ok := rhs.(*ast.CallExpr); ok && b.isCheckCall(call, sc) {
+				return call
+			}
+		}
+	case *ast.ParenExpr:
+		return b.resolveCheckCall(v.X, sc)
+	}
+
+	return nil
+}
+
+// isCheckCall 判断 call 是否为 zap.L()...Check(lvl, msg) 调用
+func (b *backend) isCheckCall(call *ast.CallExpr, sc *fileScope) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != zapMethodCheck {
+		return false
+	}
+
+	return len(call.Args) == 2 && b.isChain(sel.X, sc)
+}
+
+// isChain 检查 expr 是否包含 b 的 logger 调用链, 例如 zap.L().With(...).Info 中的 receiver。
+// 由调用链初始化的局部变量与结构体字段同样视为调用链的起点, 例如:
+//
+//	log := zap.L().With(zap.String("req", id))
+//	log.Info("...")
+func (b *backend) isChain(expr ast.Expr, sc *fileScope) bool {
+	return b.chainDepth(expr, sc, 0)
+}
+
+// chainDepth 是 isChain 的递归实现, depth 用于防止变量间相互引用导致无限递归
+func (b *backend) chainDepth(expr ast.Expr, sc *fileScope, depth int) bool {
+	switch v := expr.(type) {
+	case *ast.CallExpr:
+		// 函数可能是 SelectorExpr (zap.L()) or another call
+		if b.isRootCall(v) {
+			return true
+		}
+
+		return b.chainDepth(v.Fun, sc, depth)
+	case *ast.SelectorExpr:
+		if b.isLoggerField(v, sc) || b.isRootVar(v) {
+			return true
+		}
+
+		return b.chainDepth(v.X, sc, depth)
+	case *ast.ParenExpr:
+		return b.chainDepth(v.X, sc, depth)
+	case *ast.Ident:
+		// 局部或包级变量: 追溯其初始化表达式
+		rhs := identInitExpr(v)
+
+		return rhs != nil && b.isLoggerExpr(rhs, sc, depth+1)
+	default:
+		return false
+	}
+}
+
+// isLoggerExpr 判断 expr 的值是否为派生自调用链起点的 logger:
+// 起点调用本身, 对其调用 deriveMethods 中的方法, 或已被识别的 logger 变量与结构体字段
+func (b *backend) isLoggerExpr(expr ast.Expr, sc *fileScope, depth int) bool {
+	if depth > maxLoggerDepth {
+		return false
+	}
+
+	switch v := expr.(type) {
+	case *ast.CallExpr:
+		if b.isRootCall(v) {
+			return true
+		}
+
+		sel, ok := v.Fun.(*ast.SelectorExpr)
+		if !ok || !b.deriveMethods[sel.Sel.Name] {
+			return false
+		}
+
+		// 链式 backend 通过 With().Str(k, v)...Logger() 派生, 接收者为任意调用链
+		if b.isChainStyle() {
+			return b.chainDepth(sel.X, sc, depth+1)
+		}
+
+		return b.isLoggerExpr(sel.X, sc, depth+1)
+	case *ast.SelectorExpr:
+		return b.isLoggerField(v, sc) || b.isRootVar(v)
+	case *ast.ParenExpr:
+		return b.isLoggerExpr(v.X, sc, depth)
+	case *ast.Ident:
+		rhs := identInitExpr(v)
+
+		return rhs != nil && b.isLoggerExpr(rhs, sc, depth+1)
+	default:
+		return false
+	}
+}
+
+// isRootCall 判断 call 是否为调用链起点, 例如 zap.L()、slog.Default()
+func (b *backend) isRootCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !b.rootFuncs[sel.Sel.Name] {
+		return false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+
+	return ok && ident.Name == b.ident
+}
+
+// isRootVar 判断 sel 是否为作为调用链起点的包级变量, 例如 log.Logger
+func (b *backend) isRootVar(sel *ast.SelectorExpr) bool {
+	ident, ok := sel.X.(*ast.Ident)
+
+	return ok && b.rootVars[sel.Sel.Name] && ident.Name == b.ident && ident.Obj == nil
+}
+
+// isLoggerField 判断选择器 x.f 中的 f 是否为本文件中由 b 的调用链初始化的结构体字段
+func (b *backend) isLoggerField(sel *ast.SelectorExpr, sc *fileScope) bool {
+	return sc != nil && sc.loggerFields[sel.Sel.Name] == b
+}
+
+// newFileScope 收集文件导入的 backend、函数范围与 logger 字段, 构造 fileScope
+func newFileScope(file *ast.File, fSet *token.FileSet) *fileScope {
+	sc := &fileScope{
+		file:     file,
+		backends: importedBackends(file),
+		fns:      collectFuncRanges(file, fSet),
+	}
+
+	sc.loggerFields = collectLoggerFields(sc)
+
+	return sc
+}
+
+// collectLoggerFields 收集本文件中由 logger 调用链初始化的结构体字段名, 支持:
+//   - 复合字面量 &Service{log: zap.L().Named("svc")}
+//   - 赋值语句 s.log = zap.L().With(...)
+//
+// 字段之间可以相互派生(s.sub = s.log.Named("sub")), 因此重复扫描直到不再有新字段
+func collectLoggerFields(sc *fileScope) map[string]*backend {
+	fields := make(map[string]*backend)
+	probe := &fileScope{file: sc.file, loggerFields: fields}
+
+	for changed := true; changed; {
+		changed = false
+
+		ast.Inspect(sc.file, func(n ast.Node) bool {
+			for _, b := range sc.backends {
+				for _, name := range b.loggerFieldTargets(n, probe) {
+					if fields[name] == nil {
+						fields[name] = b
+						changed = true
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	return fields
+}
+
+// loggerFieldTargets 返回节点 n 中被赋值为 b 的 logger 的结构体字段名
+func (b *backend) loggerFieldTargets(n ast.Node, sc *fileScope) []string {
+	var names []string
+
+	switch v := n.(type) {
+	case *ast.KeyValueExpr:
+		if key, ok := v.Key.(*ast.Ident); ok && b.isLoggerExpr(v.Value, sc, 0) {
+			names = append(names, key.Name)
+		}
+	case *ast.AssignStmt:
+		if len(v.Lhs) != len(v.Rhs) {
+			return nil
+		}
+
+		for i, lhs := range v.Lhs {
+			if sel, ok := lhs.(*ast.SelectorExpr); ok && b.isLoggerExpr(v.Rhs[i], sc, 0) {
+				names = append(names, sel.Sel.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// identInitExpr 返回标识符声明时的初始化表达式(依赖 parser 的标识符解析), 支持 x := expr 与 var x = expr, 无法确定时返回 nil
+func identInitExpr(id *ast.Ident) ast.Expr {
+	if id.Obj == nil || id.Obj.Kind != ast.Var {
+		return nil
+	}
+
+	switch d := id.Obj.Decl.(type) {
+	case *ast.AssignStmt:
+		if len(d.Lhs) != len(d.Rhs) {
+			return nil
+		}
+
+		for i, lhs := range d.Lhs {
+			if l, ok := lhs.(*ast.Ident); ok && l.Name == id.Name {
+				return d.Rhs[i]
+			}
+		}
+	case *ast.ValueSpec:
+		if len(d.Names) != len(d.Values) {
+			return nil
+		}
+
+		for i, n := range d.Names {
+			if n.Name == id.Name {
+				return d.Values[i]
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+//
+// FilePath    : zap-smap\logcall_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : Check/Write 与 Log(level, ...) 调用形式单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain_LogCall_InjectsAfterMessage 测试 Log(lvl, msg, fields...) 在 msg(索引 1)之后注入
+func TestMain_LogCall_InjectsAfterMessage(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "log.go", `package sample
+
+import "go.uber.org/zap"
+
+func Foo() {
+	zap.L().Log(zap.InfoLevel, "hello", zap.String("a", "1"))
+}
+`)
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	b, err := os.ReadFile(filepath.Join(td, "log.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	s := string(b)
+	want := `zap.L().Log(zap.InfoLevel, "hello", zap.String("fl", "log.go:6"), zap.String("a", "1"))`
+	if !strings.Contains(s, want) {
+		t.Fatalf("expected field injected after msg of Log call, got:\n%s", s)
+	}
+}
+
+// TestMain_CheckWrite_InjectsIntoWriteWithCheckSite 测试 Check/Write 形式注入到 Write 调用, 位置取 Check 调用处
+func TestMain_CheckWrite_InjectsIntoWriteWithCheckSite(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "check.go", `package sample
+
+import "go.uber.org/zap"
+
+func Foo(fields []zap.Field) {
+	if ce := zap.L().Check(zap.DebugLevel, "debug"); ce != nil {
+		ce.Write(zap.String("a", "1"))
+	}
+
+	if ce := zap.L().Check(zap.InfoLevel, "info"); ce != nil {
+		ce.Write(fields...)
+	}
+
+	zap.L().Check(zap.WarnLevel, "warn").Write()
+}
+`)
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	b, err := os.ReadFile(filepath.Join(td, "check.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	s := string(b)
+
+	if !strings.Contains(s, `ce.Write(zap.String("fl", "check.go:6"), zap.String("a", "1"))`) {
+		t.Fatalf("expected Write call injected with Check site line, got:\n%s", s)
+	}
+	if !strings.Contains(s, `ce.Write(append([]zap.Field{zap.String("fl", "check.go:10")}, fields...)...)`) {
+		t.Fatalf("expected ellipsis Write call wrapped with Check site line, got:\n%s", s)
+	}
+	if !strings.Contains(s, `zap.L().Check(zap.WarnLevel, "warn").Write(zap.String("fl", "check.go:14"))`) {
+		t.Fatalf("expected chained Check().Write() injected, got:\n%s", s)
+	}
+}
+
+// TestMain_CheckWrite_LevelPolicyAndVerify 测试 Check/Log 形式遵循级别策略, 且 -verify 能识别
+func TestMain_CheckWrite_LevelPolicyAndVerify(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "check_policy.go", `package sample
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func Foo(lvl zapcore.Level) {
+	if ce := zap.L().Check(zapcore.DebugLevel, "debug"); ce != nil {
+		ce.Write(zap.String("fl", "check_policy.go:9"))
+	}
+
+	zap.L().Log(lvl, "dynamic", zap.String("fl", "check_policy.go:13"))
+	zap.L().Log(zap.ErrorLevel, "error", zap.String("fl", "check_policy.go:14"))
+}
+`)
+
+	*pathFlag = td
+	*verifyFlg = true
+	*fieldFlg = "fl"
+	*policyFlg = "Debug=skip,Log=skip"
+	os.Args = []string{"cmd"}
+
+	out := captureOutput(func() { main() })
+
+	if !strings.Contains(out, "total calls: 1") {
+		t.Fatalf("expected only the static Error Log call counted, got:\n%s", out)
+	}
+	if !strings.Contains(out, "unexpected: 2") {
+		t.Fatalf("expected Debug Check/Write and dynamic Log reported as unexpected, got:\n%s", out)
+	}
+	if !strings.Contains(out, "mismatch: 0") || !strings.Contains(out, "missing: 0") {
+		t.Fatalf("expected Error Log call verified clean, got:\n%s", out)
+	}
+}
+
+// TestMain_LoggerVars_TrackedLocalsAndFields 测试由 zap.L() 调用链初始化的局部变量、包级变量与结构体字段上的调用被注入
+func TestMain_LoggerVars_TrackedLocalsAndFields(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "vars.go", `package sample
+
+import "go.uber.org/zap"
+
+var pkgLog = zap.L().Named("pkg")
+
+type Service struct {
+	log *zap.Logger
+	sub *zap.Logger
+}
+
+func NewService() *Service {
+	s := &Service{log: zap.L().Named("svc")}
+	s.sub = s.log.With(zap.String("part", "sub"))
+
+	return s
+}
+
+func (s *Service) Run(id string) {
+	log := zap.L().With(zap.String("req", id))
+	child := log.WithOptions(zap.AddCallerSkip(1))
+	sugar := zap.L().Sugar()
+
+	log.Info("local")
+	child.Warn("derived")
+	pkgLog.Error("package")
+	s.log.Info("field")
+	s.sub.Debug("derived field")
+	sugar.Info("sugar")
+}
+`)
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	b, err := os.ReadFile(filepath.Join(td, "vars.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	s := string(b)
+
+	wants := []string{
+		`log.Info("local", zap.String("fl", "vars.go:24"))`,
+		`child.Warn("derived", zap.String("fl", "vars.go:25"))`,
+		`pkgLog.Error("package", zap.String("fl", "vars.go:26"))`,
+		`s.log.Info("field", zap.String("fl", "vars.go:27"))`,
+		`s.sub.Debug("derived field", zap.String("fl", "vars.go:28"))`,
+	}
+	for _, w := range wants {
+		if !strings.Contains(s, w) {
+			t.Fatalf("expected %s, got:\n%s", w, s)
+		}
+	}
+
+	// Sugar() 不是派生 *zap.Logger 的方法, 不应被视为 logger 变量
+	if !strings.Contains(s, `sugar.Info("sugar")`) {
+		t.Fatalf("expected sugared logger call untouched, got:\n%s", s)
+	}
+}
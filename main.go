@@ -7,7 +7,10 @@
 //
 
 // zap 日志打印注入 file:line 信息工具, 在导入了 go.uber.org/zap 的源码文件中.
-// 自动在 zap.L().Info/Error/Debug/Warn/... 调用处注入 zap.String("fl","file:line")
+// 自动在 zap.L().Info/Error/Debug/Warn/... 调用处注入 zap.String("fl","file:line"),
+// 导入了 log/slog 的文件则在 slog.Info/Error/... 调用处注入 slog.String("fl","file:line"),
+// 导入了 zerolog 的文件则在 log.Info()...Msg(...) 事件调用链中插入 .Str("fl","file:line").
+// 加上 -token 时注入位置的 HMAC 令牌并生成 smap.json, 通过 `zap-smap decode` 将日志中的令牌还原为源码位置
 // 使用方法(在仓库根目录运行):
 //
 // 查看帮助:
@@ -36,12 +39,34 @@ var (
 	BuildTime = "unknown"
 )
 
+// subcommands 以第一个命令行参数区分的子命令, 子命令自行解析其后的参数
+var subcommands = map[string]func(args []string) error{
+	"decode":       func(args []string) error { return runDecode(args, os.Stdin, os.Stdout) },
+	"locate":       func(args []string) error { return runLocate(args, os.Stdin, os.Stdout) },
+	"audit-binary": func(args []string) error { return runAudit(args, os.Stdout) },
+	"merge-driver": runMergeDriver,
+	"filter":       func(args []string) error { return runFilter(args, os.Stdin, os.Stdout) },
+	"hotspots":     func(args []string) error { return runHotspots(args, os.Stdin, os.Stdout) },
+}
+
 func main() {
 	// 尝试使用 build info 填充版本信息(仅当 ldflags 未注入时生效)
 	if bi, ok := debug.ReadBuildInfo(); ok {
 		applyBuildInfo(bi)
 	}
 
+	// 子命令(例如 decode)自行解析其后的参数
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+				os.Exit(1)
+			}
+
+			return
+		}
+	}
+
 	// 解析命令行参数
 	flag.Parse()
 
@@ -57,6 +82,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 解析 -level-policy、-backends、-token、-locs、-garble-scope 与 -rel-to 参数
+	if err := initFormatModes(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
 	// 获取目标路径
 	target := *pathFlag
 
@@ -73,8 +104,29 @@ func main() {
 	// 读取 module path(可选), 用于生成完整函数路径
 	modulePath := readModulePath(baseDir)
 
-	// 解析 -exclude 参数
-	parseExcludeList(baseDir)
+	// 解析 -exclude、-include 等跳过规则
+	if err := initSkipRules(baseDir); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	// 解析 -for 参数, 得到构建依赖图中的文件
+	if err := initBuildGraph(baseDir); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	// 位置参数为 go 风格的包模式或文件列表, 一次处理全部目标
+	if flag.NArg() > 0 {
+		if err := runTargetsMode(flag.Args(), fSet, modulePath, baseDir); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		exitOnFailures()
+
+		return
+	}
 
 	// 支持两种用法, 传入目录(默认)或传入单个文件路径
 	if fi, err := os.Stat(target); err == nil && !fi.IsDir() {
@@ -90,6 +142,16 @@ func main() {
 			os.Exit(1)
 		}
 	}
+
+	exitOnFailures()
+}
+
+// exitOnFailures 打印 -continue-on-error 模式下收集的失败, 存在失败时以非零状态退出
+func exitOnFailures() {
+	if err := reportFailures(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 // applyBuildInfo 根据 debug.BuildInfo 填充版本信息。
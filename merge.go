@@ -0,0 +1,195 @@
+//
+// FilePath    : zap-smap\merge.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : merge-driver 子命令, 去除注入字段后三方合并再重新注入, 消除只由位置值引起的冲突
+//
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jiaopengzi/go-utils"
+)
+
+// runMergeDriver git 合并驱动, 在 .gitattributes 中配置为 zap-smap merge-driver %O %A %B %P:
+// 先去除三个版本中由工具注入的字段并执行 git merge-file, 合并无冲突时以 processSource 重新注入并写回 %A,
+// -token 与 -locs 模式下同时更新映射文件与位置常量表;
+// 仍有冲突时按原始内容执行普通的三方合并, 在 %A 中留下冲突标记并返回错误
+func runMergeDriver(args []string) error {
+	fs := flag.NewFlagSet("merge-driver", flag.ContinueOnError)
+	bindFormatFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 4 {
+		return fmt.Errorf("usage: zap-smap merge-driver [flags] %%O %%A %%B %%P")
+	}
+
+	if err := initFormatModes(); err != nil {
+		return err
+	}
+
+	base, ours, theirs, path := fs.Arg(0), fs.Arg(1), fs.Arg(2), fs.Arg(3)
+	baseDir := *pathFlag
+	modulePath := readModulePath(baseDir)
+
+	merged, conflicts, err := mergeStripped(base, ours, theirs, path)
+	if err != nil {
+		return err
+	}
+
+	if conflicts > 0 {
+		// 去除注入字段后仍有冲突: 保留原始内容的普通冲突, 交由用户解决
+		if _, _, err := gitMergeFile(ours, base, theirs, false); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("%s: %d conflicts remain", path, conflicts)
+	}
+
+	out, err := injectSource(path, merged, modulePath, baseDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(ours, out, 0644); err != nil {
+		return err
+	}
+
+	if err := writeSidecars(path, out, modulePath, baseDir); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "[MERGE] %s: merged with injected fields re-generated\n", path)
+
+	return nil
+}
+
+// mergeStripped 去除三个版本中的注入字段后执行三方合并, 返回合并结果与冲突数
+func mergeStripped(base, ours, theirs, path string) ([]byte, int, error) {
+	files := []string{ours, base, theirs}
+	stripped := make([]string, 0, len(files))
+
+	for _, f := range files {
+		src, err := utils.ReadFile(f)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		tmp, err := os.CreateTemp("", "zap-smap-merge-*.go")
+		if err != nil {
+			return nil, 0, err
+		}
+
+		name := tmp.Name()
+		defer os.Remove(name)
+
+		_, err = tmp.Write(stripInjected(path, src))
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		stripped = append(stripped, name)
+	}
+
+	return gitMergeFile(stripped[0], stripped[1], stripped[2], true)
+}
+
+// stripOwned 为 true 时 processSource 对所有级别按 policySkip 处理, 只移除由工具注入的字段,
+// 开发者自己记录的同名字段(例如 zap.Int("fl", floor))保持不变
+var stripOwned bool
+
+// stripInjected 去除 src 中由工具注入的字段, 非 Go 文件或无需修改时返回原内容
+func stripInjected(path string, src []byte) []byte {
+	if shouldSkipFile(path) {
+		return src
+	}
+
+	stripOwned = true
+
+	defer func() { stripOwned = false }()
+
+	modified, out, _, err := processSource(path, src, token.NewFileSet(), "", "")
+	if err != nil || !modified {
+		return src
+	}
+
+	return []byte(out)
+}
+
+// injectSource 按 path 对应的位置向 src 注入字段, 非 Go 文件或无需修改时返回原内容
+func injectSource(path string, src []byte, modulePath, baseDir string) ([]byte, error) {
+	if shouldSkipFile(path) {
+		return src, nil
+	}
+
+	// 无法解析的内容(例如仍含冲突标记)原样返回, 交由 git 处理
+	modified, out, _, err := processSource(path, src, token.NewFileSet(), modulePath, baseDir)
+	if isParseError(err) {
+		return src, nil
+	}
+
+	if err != nil || !modified {
+		return src, err
+	}
+
+	return []byte(out), nil
+}
+
+// writeSidecars 按 path 的新内容更新 -token 模式的映射文件与 -locs 模式的位置常量表, 其他模式下不做任何事
+func writeSidecars(path string, out []byte, modulePath, baseDir string) error {
+	saved := *writeFlg
+	*writeFlg = true
+
+	defer func() { *writeFlg = saved }()
+
+	recordSites(path, true, string(out), modulePath, baseDir)
+	recordLocs(path, true, string(out), modulePath, baseDir)
+
+	if err := writeLocTables(modulePath, baseDir); err != nil {
+		return err
+	}
+
+	return writeSourceMap([]string{siteRel(path, baseDir)}, baseDir)
+}
+
+// gitMergeFile 执行 git merge-file, stdout 为 true 时返回合并结果, 否则将结果写回 current; 返回冲突数
+func gitMergeFile(current, base, other string, stdout bool) ([]byte, int, error) {
+	args := []string{"merge-file", "-L", "ours", "-L", "base", "-L", "theirs"}
+	if stdout {
+		args = append(args, "-p")
+	}
+
+	cmd := exec.Command("git", append(args, current, base, other)...)
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err == nil {
+		return out, 0, nil
+	}
+
+	// 退出码为冲突数, 负数(255)表示出错
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ee.ExitCode() > 0 && ee.ExitCode() < 128 {
+		return out, ee.ExitCode(), nil
+	}
+
+	return nil, 0, fmt.Errorf("git merge-file: %v: %s", err, strings.TrimSpace(stderr.String()))
+}
@@ -0,0 +1,288 @@
+//
+// FilePath    : zap-smap\merge_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : merge-driver 子命令单测
+//
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// mergeBase 合并单测的共同祖先版本
+const mergeBase = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("started", zap.String("fl", "svc/a.go:6"))
+}
+
+func Stop() {
+	zap.L().Info("stopped", zap.String("fl", "svc/a.go:10"))
+}
+`
+
+// mergeOurs 在 Run 中插入一行, 之后的位置值均后移
+const mergeOurs = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	prepare()
+	zap.L().Info("started", zap.String("fl", "svc/a.go:7"))
+}
+
+func Stop() {
+	zap.L().Info("stopped", zap.String("fl", "svc/a.go:11"))
+}
+`
+
+// mergeTheirs 在 Stop 中插入一行, Stop 中的位置值后移
+const mergeTheirs = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("started", zap.String("fl", "svc/a.go:6"))
+}
+
+func Stop() {
+	cleanup()
+	zap.L().Info("stopped", zap.String("fl", "svc/a.go:11"))
+}
+`
+
+// mergeResolved mergeOurs 与 mergeTheirs 合并并重新注入后的结果
+const mergeResolved = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	prepare()
+	zap.L().Info("started", zap.String("fl", "svc/a.go:7"))
+}
+
+func Stop() {
+	cleanup()
+	zap.L().Info("stopped", zap.String("fl", "svc/a.go:12"))
+}
+`
+
+// writeMergeFiles 写出 %O %A %B 三个版本, 返回其路径
+func writeMergeFiles(t *testing.T, base, ours, theirs string) (string, string, string) {
+	t.Helper()
+
+	td := t.TempDir()
+	writeFile(t, td, "base", base)
+	writeFile(t, td, "ours", ours)
+	writeFile(t, td, "theirs", theirs)
+
+	return filepath.Join(td, "base"), filepath.Join(td, "ours"), filepath.Join(td, "theirs")
+}
+
+// TestRunMergeDriver_ResolvesLocationConflicts 测试只由位置值引起的冲突被自动解决, 并按合并结果重新注入
+func TestRunMergeDriver_ResolvesLocationConflicts(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	base, ours, theirs := writeMergeFiles(t, mergeBase, mergeOurs, mergeTheirs)
+
+	// git 在仓库根目录运行合并驱动, %P 相对于仓库根
+	t.Chdir(t.TempDir())
+
+	if err := runMergeDriver([]string{"-field", "fl", base, ours, theirs, "svc/a.go"}); err != nil {
+		t.Fatalf("merge driver: %v", err)
+	}
+
+	got, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	if string(got) != mergeResolved {
+		t.Fatalf("unexpected merge result:\n%s\nwant:\n%s", got, mergeResolved)
+	}
+}
+
+// TestRunMergeDriver_KeepsDeveloperField 测试开发者自己记录的同名字段在合并后保持不变, 只输出冲突警告
+func TestRunMergeDriver_KeepsDeveloperField(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	own := regexp.MustCompile(`"stopped", zap.String\("fl", "svc/a.go:\d+"\)`)
+	dev := func(src string) string {
+		return own.ReplaceAllLiteralString(src, `"stopped", zap.Int("fl", 3)`)
+	}
+
+	base, ours, theirs := writeMergeFiles(t, dev(mergeBase), dev(mergeOurs), dev(mergeTheirs))
+
+	t.Chdir(t.TempDir())
+
+	out := captureOutput(func() {
+		if err := runMergeDriver([]string{"-field", "fl", base, ours, theirs, "svc/a.go"}); err != nil {
+			t.Errorf("merge driver: %v", err)
+		}
+	})
+
+	got, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	for _, w := range []string{`zap.String("fl", "svc/a.go:7")`, `zap.L().Info("stopped", zap.Int("fl", 3))`} {
+		if !strings.Contains(string(got), w) {
+			t.Fatalf("expected %s in merge result:\n%s", w, got)
+		}
+	}
+
+	if !strings.Contains(out, "collides with zap.Int(\"fl\", 3)") {
+		t.Fatalf("expected collision warning, got:\n%s", out)
+	}
+}
+
+// TestRunMergeDriver_TokenMode 测试 -token 模式下合并后重新注入令牌而不是 file:line, 并更新映射文件
+func TestRunMergeDriver_TokenMode(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	// 令牌由 文件:行号 | 包名.函数名 计算, 函数名取位置值之前最近的 func 声明
+	site := regexp.MustCompile(`func (\w+)\(\)|"svc/a.go:(\d+)"`)
+	tokenize := func(src string) string {
+		tokenKey = []byte("secret")
+		fn := ""
+
+		return site.ReplaceAllStringFunc(src, func(m string) string {
+			sm := site.FindStringSubmatch(m)
+			if sm[1] != "" {
+				fn = "svc." + sm[1]
+				return m
+			}
+
+			line, _ := strconv.Atoi(sm[2])
+
+			return strconv.Quote(siteToken("svc/a.go", line, fn))
+		})
+	}
+
+	base, ours, theirs := writeMergeFiles(t, tokenize(mergeBase), tokenize(mergeOurs), tokenize(mergeTheirs))
+	want := tokenize(mergeResolved)
+
+	root := t.TempDir()
+	t.Chdir(root)
+
+	captureOutput(func() {
+		if err := runMergeDriver([]string{"-field", "fl", "-token", "-token-key", "secret", base, ours, theirs, "svc/a.go"}); err != nil {
+			t.Errorf("merge driver: %v", err)
+		}
+	})
+
+	got, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	if string(got) != want || strings.Contains(string(got), "svc/a.go:") {
+		t.Fatalf("unexpected merge result:\n%s\nwant:\n%s", got, want)
+	}
+
+	sm, err := loadSourceMap(filepath.Join(root, "smap.json"))
+	if err != nil {
+		t.Fatalf("load smap: %v", err)
+	}
+
+	if len(sm.Entries) != 2 || sm.Entries[siteToken("svc/a.go", 12, "svc.Stop")].Line != 12 {
+		t.Fatalf("unexpected smap entries: %v", sm.Entries)
+	}
+}
+
+// TestRunMergeDriver_LocsMode 测试 -locs 模式下合并保留位置常量引用, 并重新生成位置常量表
+func TestRunMergeDriver_LocsMode(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	site := regexp.MustCompile(`"svc/a.go:\d+"`)
+	idents := func(src string) string {
+		n := 0
+
+		return site.ReplaceAllStringFunc(src, func(string) string {
+			n++
+
+			return fmt.Sprintf("smapLoc_0000000%d", n)
+		})
+	}
+
+	base, ours, theirs := writeMergeFiles(t, idents(mergeBase), idents(mergeOurs), idents(mergeTheirs))
+
+	root := t.TempDir()
+	t.Chdir(root)
+
+	if err := os.Mkdir("svc", 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	captureOutput(func() {
+		if err := runMergeDriver([]string{"-field", "fl", "-locs", base, ours, theirs, "svc/a.go"}); err != nil {
+			t.Errorf("merge driver: %v", err)
+		}
+	})
+
+	got, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	if string(got) != idents(mergeResolved) {
+		t.Fatalf("unexpected merge result:\n%s\nwant:\n%s", got, idents(mergeResolved))
+	}
+
+	table, err := os.ReadFile(filepath.Join(root, "svc", locsFileName))
+	if err != nil {
+		t.Fatalf("read locs table: %v", err)
+	}
+
+	for _, w := range []string{`smapLoc_00000001 = "svc/a.go:7"`, `smapLoc_00000002 = "svc/a.go:12"`} {
+		if !strings.Contains(string(table), w) {
+			t.Fatalf("expected %s in locs table:\n%s", w, table)
+		}
+	}
+}
+
+// TestRunMergeDriver_RealConflict 测试去除注入字段后仍有冲突时保留原始内容的普通冲突
+func TestRunMergeDriver_RealConflict(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	theirs := strings.Replace(mergeTheirs, `"stopped"`, `"halted"`, 1)
+	ours := strings.Replace(mergeOurs, `"stopped"`, `"finished"`, 1)
+	base, oursPath, theirsPath := writeMergeFiles(t, mergeBase, ours, theirs)
+
+	err := runMergeDriver([]string{"-field", "fl", base, oursPath, theirsPath, "svc/a.go"})
+	if err == nil || !strings.Contains(err.Error(), "svc/a.go: 1 conflicts remain") {
+		t.Fatalf("expected remaining conflict, got %v", err)
+	}
+
+	got, err := os.ReadFile(oursPath)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	for _, w := range []string{"<<<<<<< ours", `zap.String("fl", "svc/a.go:11")`, ">>>>>>> theirs"} {
+		if !strings.Contains(string(got), w) {
+			t.Fatalf("expected %q in conflict result:\n%s", w, got)
+		}
+	}
+
+	if err := runMergeDriver([]string{base, oursPath}); err == nil {
+		t.Fatalf("expected usage error")
+	}
+}
@@ -0,0 +1,264 @@
+//
+// FilePath    : zap-smap\module.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 按文件解析所属 module、go.work 与仓库根目录, 决定注入路径的基准目录
+//
+
+package main
+
+import (
+	"fmt"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jiaopengzi/go-utils"
+)
+
+// -rel-to 取值, 决定注入的路径相对于哪个目录
+const (
+	relToPath      = "path"      // -path 指定的目录(单文件模式为当前工作目录), 导入路径取自该目录的 go.mod
+	relToModule    = "module"    // 文件所属 module 的根目录(最近的 go.mod)
+	relToWorkspace = "workspace" // go.work 所在目录, 文件所属 module 不在 use 列表中时同 module
+	relToRepo      = "repo"      // 仓库根目录(最近的 .git), 找不到时同 module
+)
+
+// moduleRoot 文件所属 module 的根目录与 module path, dir 为空表示不属于任何 module
+type moduleRoot struct {
+	dir  string
+	path string
+}
+
+// workspace go.work 所在目录与 use 指令列出的 module 目录(绝对路径)
+type workspace struct {
+	dir  string
+	uses map[string]bool
+}
+
+// 按目录缓存的解析结果, 每个调用位置都会查询, 由 initRelTo 重置
+var (
+	moduleRoots map[string]moduleRoot
+	workspaces  map[string]*workspace
+	repoRoots   map[string]string
+)
+
+// initRelTo 校验 -rel-to 参数并重置按目录缓存的解析结果
+func initRelTo() error {
+	moduleRoots = make(map[string]moduleRoot)
+	workspaces = make(map[string]*workspace)
+	repoRoots = make(map[string]string)
+
+	switch *relToFlg {
+	case relToPath, relToModule, relToWorkspace, relToRepo:
+		return nil
+	default:
+		return fmt.Errorf("unknown -rel-to %q, expected path, module, workspace or repo", *relToFlg)
+	}
+}
+
+// absDir 返回 path 所在目录的绝对路径, path 为目录时返回其本身
+func absDir(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = filepath.Clean(path)
+	}
+
+	if fi, err := os.Stat(abs); err == nil && fi.IsDir() {
+		return abs
+	}
+
+	return filepath.Dir(abs)
+}
+
+// findModuleRoot 自 dir 向上查找最近的 go.mod, 返回其所在目录与 module path
+func findModuleRoot(dir string) moduleRoot {
+	if mr, ok := moduleRoots[dir]; ok {
+		return mr
+	}
+
+	var mr moduleRoot
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if fi, err := os.Stat(filepath.Join(d, "go.mod")); err == nil && !fi.IsDir() {
+			mr = moduleRoot{dir: d, path: readModulePath(d)}
+			break
+		}
+
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if moduleRoots != nil {
+		moduleRoots[dir] = mr
+	}
+
+	return mr
+}
+
+// findWorkspace 返回 dir 所在的 go.work 工作区: 环境变量 GOWORK=off 时不使用工作区,
+// GOWORK 为文件路径时使用该文件, 否则自 dir 向上查找最近的 go.work; 未找到返回 nil
+func findWorkspace(dir string) *workspace {
+	workFile := os.Getenv("GOWORK")
+
+	switch workFile {
+	case "off":
+		return nil
+	case "":
+		for d := dir; ; d = filepath.Dir(d) {
+			if fi, err := os.Stat(filepath.Join(d, "go.work")); err == nil && !fi.IsDir() {
+				workFile = filepath.Join(d, "go.work")
+				break
+			}
+
+			if filepath.Dir(d) == d {
+				return nil
+			}
+		}
+	}
+
+	if ws, ok := workspaces[workFile]; ok {
+		return ws
+	}
+
+	ws := readWorkspace(workFile)
+
+	if workspaces != nil {
+		workspaces[workFile] = ws
+	}
+
+	return ws
+}
+
+// readWorkspace 解析 go.work 中的 use 指令(单行与括号块两种形式), 读取失败返回 nil
+func readWorkspace(workFile string) *workspace {
+	b, err := utils.ReadFile(workFile)
+	if err != nil {
+		return nil
+	}
+
+	ws := &workspace{dir: filepath.Dir(workFile), uses: make(map[string]bool)}
+	inBlock := false
+
+	for line := range strings.SplitSeq(string(b), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		line = strings.TrimSpace(line)
+
+		var use string
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock:
+			use = line
+		case line == "use (" || line == "use(":
+			inBlock = true
+		default:
+			if after, ok := strings.CutPrefix(line, "use "); ok {
+				use = strings.TrimSpace(after)
+			}
+		}
+
+		if use == "" {
+			continue
+		}
+
+		if s, err := strconv.Unquote(use); err == nil {
+			use = s
+		}
+
+		if !filepath.IsAbs(use) {
+			use = filepath.Join(ws.dir, use)
+		}
+
+		ws.uses[filepath.Clean(use)] = true
+	}
+
+	return ws
+}
+
+// findRepoRoot 自 dir 向上查找包含 .git 的目录(.git 可以是 worktree 的文件), 未找到返回空串
+func findRepoRoot(dir string) string {
+	if root, ok := repoRoots[dir]; ok {
+		return root
+	}
+
+	root := ""
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			root = d
+			break
+		}
+
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if repoRoots != nil {
+		repoRoots[dir] = root
+	}
+
+	return root
+}
+
+// relRoot 按 -rel-to 返回 path 的注入路径所相对的目录, 找不到对应目录时依次退回 module 根目录与 baseDir
+func relRoot(path, baseDir string) string {
+	if *relToFlg == relToPath {
+		return baseDir
+	}
+
+	dir := absDir(path)
+	mr := findModuleRoot(dir)
+
+	switch *relToFlg {
+	case relToWorkspace:
+		if ws := findWorkspace(dir); ws != nil && mr.dir != "" && ws.uses[mr.dir] {
+			return ws.dir
+		}
+	case relToRepo:
+		if root := findRepoRoot(dir); root != "" {
+			return root
+		}
+	}
+
+	if mr.dir != "" {
+		return mr.dir
+	}
+
+	return baseDir
+}
+
+// siteRel 返回注入值中 path 的相对路径('/' 分隔), 基准目录由 -rel-to 决定
+func siteRel(path, baseDir string) string {
+	return relPath(path, relRoot(path, baseDir))
+}
+
+// fileImportPath 返回文件所在包的导入路径: 文件属于某个 module 时为其 module path 加上文件相对 module 根的目录;
+// -rel-to path 或找不到 module 时为 modulePath 加上 rel 的目录; 均无 module path 时返回空串
+func fileImportPath(path, rel, modulePath string) string {
+	if *relToFlg != relToPath {
+		if mr := findModuleRoot(absDir(path)); mr.dir != "" && mr.path != "" {
+			modulePath = mr.path
+			rel = relPath(path, mr.dir)
+		}
+	}
+
+	if modulePath == "" {
+		return ""
+	}
+
+	if dir := pathpkg.Dir(filepath.ToSlash(rel)); dir != "." && dir != "" {
+		return pathpkg.Join(modulePath, dir)
+	}
+
+	return modulePath
+}
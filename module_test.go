@@ -0,0 +1,128 @@
+//
+// FilePath    : zap-smap\module_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : module、go.work 与 -rel-to 单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// moduleSample 嵌套 module 中的源码, Info 调用位于第 6 行
+const moduleSample = `package svc
+
+import "go.uber.org/zap"
+
+func Run() {
+	zap.L().Info("run")
+}
+`
+
+// workspaceFiles 仓库: ws/go.work 使用 ws/a, ws/tools 为不在 use 列表中的 module
+var workspaceFiles = map[string]string{
+	".git/":             "",
+	"ws/go.work":        "go 1.25\n\nuse (\n\t./a // service\n)\n",
+	"ws/a/go.mod":       "module example.com/a\n",
+	"ws/a/svc/x.go":     moduleSample,
+	"ws/tools/go.mod":   "module example.com/tools\n",
+	"ws/tools/svc/x.go": moduleSample,
+}
+
+// TestSiteRel_RelTo 测试各 -rel-to 取值下注入路径与导入路径的解析
+func TestSiteRel_RelTo(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	td := writeTree(t, workspaceFiles)
+	a := filepath.Join(td, "ws", "a", "svc", "x.go")
+	tools := filepath.Join(td, "ws", "tools", "svc", "x.go")
+
+	cases := []struct {
+		relTo, path, want string
+	}{
+		{relToModule, a, "svc/x.go"},
+		{relToWorkspace, a, "a/svc/x.go"},
+		{relToWorkspace, tools, "svc/x.go"},
+		{relToRepo, a, "ws/a/svc/x.go"},
+		{relToPath, a, "ws/a/svc/x.go"},
+	}
+
+	for _, c := range cases {
+		resetGlobals()
+
+		*relToFlg = c.relTo
+		if err := initRelTo(); err != nil {
+			t.Fatalf("initRelTo: %v", err)
+		}
+
+		if got := siteRel(c.path, td); got != c.want {
+			t.Fatalf("-rel-to %s: siteRel(%s) = %q, want %q", c.relTo, c.path, got, c.want)
+		}
+	}
+
+	// 导入路径取自文件所属 module, -rel-to path 时取自 -path 的 go.mod
+	resetGlobals()
+
+	*relToFlg = relToModule
+
+	if got := fileImportPath(tools, "ws/tools/svc/x.go", ""); got != "example.com/tools/svc" {
+		t.Fatalf("unexpected import path %q", got)
+	}
+
+	*relToFlg = relToPath
+
+	if got := fileImportPath(tools, "ws/tools/svc/x.go", "example.com/repo"); got != "example.com/repo/ws/tools/svc" {
+		t.Fatalf("unexpected import path with -rel-to path %q", got)
+	}
+
+	// GOWORK=off 时不使用工作区
+	t.Setenv("GOWORK", "off")
+
+	*relToFlg = relToWorkspace
+	if got := siteRel(a, td); got != "svc/x.go" {
+		t.Fatalf("expected module-relative path with GOWORK=off, got %q", got)
+	}
+
+	*relToFlg = "cwd"
+	if err := initRelTo(); err == nil {
+		t.Fatalf("expected error for unknown -rel-to")
+	}
+
+	resetGlobals()
+}
+
+// TestSingleFileMode_NestedModule 测试 -rel-to module 时在子目录中以单文件模式运行按文件所属 module 计算路径与函数名
+func TestSingleFileMode_NestedModule(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	td := writeTree(t, workspaceFiles)
+
+	resetGlobals()
+	defer resetGlobals()
+
+	t.Chdir(filepath.Join(td, "ws", "a", "svc"))
+
+	*pathFlag = "x.go"
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*funcFlg = true
+	*relToFlg = relToModule
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	b, err := os.ReadFile("x.go")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if !strings.Contains(string(b), `zap.String("fl", "svc/x.go:6 | example.com/a/svc.Run")`) {
+		t.Fatalf("unexpected injection:\n%s", b)
+	}
+}
@@ -10,6 +10,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -19,7 +20,8 @@ import (
 )
 
 // applyPatchIfModified 将修改写回文件或打印预览, 基于 -write 标志。
-// 会先比较新旧内容, 只有实际发生变化的文件才输出 [PATCH] 并执行写回或预览。
+// 会先比较新旧内容, 只有实际发生变化且通过类型检查的文件才输出 [PATCH] 并执行写回或预览,
+// dry-run 与 -write 的结果一致。
 func applyPatchIfModified(path string, modified bool, out string, modifiedLines []int, baseDir string) error {
 	if !modified {
 		return nil
@@ -31,6 +33,13 @@ func applyPatchIfModified(path string, modified bool, out string, modifiedLines
 		return nil
 	}
 
+	// 改写会引入编译错误(例如 zap 标识符被遮蔽)时拒绝写回, dry-run 同样报告
+	if *typeChkFlg {
+		if err := typeCheckRewrite(path, original, out); err != nil {
+			return err
+		}
+	}
+
 	rel := relPath(path, baseDir)
 
 	fmt.Printf("[PATCH] %s\n", rel)
@@ -38,7 +47,7 @@ func applyPatchIfModified(path string, modified bool, out string, modifiedLines
 	if *writeFlg {
 		// 写回文件(内容已经通过 go/format.Source 格式化)
 		if err := os.WriteFile(path, []byte(out), 0600); err != nil {
-			return err
+			return &fileError{op: opWrite, path: path, err: err}
 		}
 	} else {
 		// dry-run: 打印预览片段
@@ -80,17 +89,22 @@ func printPreview(out, path string, modifiedLines []int) {
 		sort.Ints(numList)
 
 		for _, l := range numList {
-			start := max(l-3, 1)
-
-			end := min(l+3, len(lines))
-
-			fmt.Printf("--- snippet around L%d (%s) ---\n", l, path)
-
-			for i := start; i <= end; i++ {
-				fmt.Printf("%5d: %s\n", i, lines[i-1])
-			}
+			printSnippet(os.Stdout, lines, path, l)
 		}
 	}
 
 	fmt.Println("--- end preview ---")
 }
+
+// printSnippet 打印第 l 行前后各 3 行的源码片段
+func printSnippet(w io.Writer, lines []string, path string, l int) {
+	start := max(l-3, 1)
+
+	end := min(l+3, len(lines))
+
+	fmt.Fprintf(w, "--- snippet around L%d (%s) ---\n", l, path)
+
+	for i := start; i <= end; i++ {
+		fmt.Fprintf(w, "%5d: %s\n", i, lines[i-1])
+	}
+}
@@ -15,7 +15,6 @@ import (
 	"go/parser"
 	"go/printer"
 	"go/token"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,26 +27,45 @@ func processFile(path string, fSet *token.FileSet, modulePath string, baseDir st
 	// 读取文件内容
 	src, err := utils.ReadFile(path)
 	if err != nil {
-		return false, "", nil, err
+		return false, "", nil, &fileError{op: opRead, path: path, err: err}
 	}
 
+	return processSource(path, src, fSet, modulePath, baseDir)
+}
+
+// processSource 对 path 的源码 src 执行 AST 修改, path 仅用于计算注入的位置, 返回值同 processFile
+func processSource(path string, src []byte, fSet *token.FileSet, modulePath string, baseDir string) (bool, string, []int, error) {
 	// 解析文件为 AST
 	file, err := parser.ParseFile(fSet, path, src, parser.ParseComments)
 	if err != nil {
-		// 解析失败时至少记录警告, 避免静默跳过
-		fmt.Fprintf(os.Stderr, "warn: parse %s failed: %v\n", path, err)
+		// 解析失败由调用方决定输出警告或计入失败, 避免静默跳过
+		return false, "", nil, &fileError{op: opParse, path: path, err: err}
+	}
+
+	// 收集导入的日志库、函数范围与 logger 变量等文件级信息
+	sc := newFileScope(file, fSet)
+
+	// 未导入任何支持的日志库, 无需处理
+	if len(sc.backends) == 0 {
 		return false, "", nil, nil
 	}
 
-	// 判断是否包含 zap 导入
-	if !hasZapImport(file) {
+	// -garble-scope 模式: GOGARBLE 范围之外的文件不处理, 或按 -garble-strip 移除注入字段
+	if !applyGarbleScope(sc, path, modulePath, baseDir) {
 		return false, "", nil, nil
 	}
 
-	modified := false
+	// 只去除注入字段时不区分级别, 也不分配位置常量名
+	if stripOwned {
+		sc.policy = policySkip
+	}
 
-	// 收集函数范围信息
-	fns := collectFuncRanges(file, fSet)
+	// -locs 模式: 为每个注入目标分配位置常量名
+	if locTables != nil && !stripOwned {
+		sc.locIdents = assignLocIdents(sc, fSet, path, baseDir)
+	}
+
+	modified := false
 
 	var modifiedLines []int
 
@@ -59,14 +77,8 @@ func processFile(path string, fSet *token.FileSet, modulePath string, baseDir st
 			return true
 		}
 
-		// 处理 SelectorExpr 函数调用
-		sel, ok := ce.Fun.(*ast.SelectorExpr)
-		if !ok {
-			return true
-		}
-
 		// 将复杂逻辑委托给 handleCallExpr, 便于拆分和测试
-		if ok, line := handleCallExpr(ce, sel, fSet, file, fns, modulePath, baseDir); ok {
+		if ok, line := handleCallExpr(ce, fSet, sc, modulePath, baseDir); ok {
 			modified = true
 
 			modifiedLines = append(modifiedLines, line)
@@ -82,18 +94,16 @@ func processFile(path string, fSet *token.FileSet, modulePath string, baseDir st
 			return false, "", nil, err
 		}
 
-		out := sb.String()
+		out := formatSource(sb.String())
 
-		// 二次修正: go/printer 可能重排代码行(如 CompositeLit 被拆行),
-		// 导致注入的行号与实际行号不符。重新解析输出, 校正行号。
-		if *delFlg == "" {
-			out = correctLineNumbers(out, path, modulePath, baseDir)
-		}
-
-		// 使用 go/format 格式化输出, 保证与 gofmt 一致
-		formatted, err := format.Source([]byte(out))
-		if err == nil {
-			out = string(formatted)
+		// 二次修正: go/printer 与 go/format 可能重排代码行(如 CompositeLit 被拆行),
+		// 导致注入的行号与实际行号不符。重新解析输出校正行号并再次格式化, 直到输出不再变化。
+		// -locs 模式下行号只出现在位置常量表中, 无需修正。
+		if *delFlg == "" && locTables == nil && sc.policy == "" {
+			var err error
+			if out, err = fixLineNumbers(out, path, modulePath, baseDir); err != nil {
+				return false, "", nil, err
+			}
 		}
 
 		return true, out, modifiedLines, nil
@@ -103,216 +113,237 @@ func processFile(path string, fSet *token.FileSet, modulePath string, baseDir st
 }
 
 // handleCallExpr 处理单个 CallExpr, 返回是否修改以及修改所在的行号
-func handleCallExpr(ce *ast.CallExpr, sel *ast.SelectorExpr, fSet *token.FileSet, file *ast.File, fns []fnRange, modulePath, baseDir string) (bool, int) {
-	method := sel.Sel.Name
-	if !logMethods[method] {
-		return false, 0
-	}
-
-	if !isZapChain(sel.X) {
+func handleCallExpr(ce *ast.CallExpr, fSet *token.FileSet, sc *fileScope, modulePath, baseDir string) (bool, int) {
+	lc, ok := matchLogCall(ce, sc)
+	if !ok {
 		return false, 0
 	}
 
-	if len(ce.Args) == 0 {
+	// 只去除注入字段时保留 -locs 模式的位置常量引用: 常量名与行号无关, 不会引起合并冲突
+	if stripOwned && locTables != nil && currentLocIdent(lc) != "" {
 		return false, 0
 	}
 
 	// 如果指定了要删除的字段, 执行纯删除操作后立即返回, 不再注入新字段
 	if *delFlg != "" {
-		return handleDeleteField(ce, fSet)
+		return handleDeleteField(lc, fSet)
+	}
+
+	// 级别策略为 skip/strip 时不注入, 并清理此前注入的字段
+	if policy := sc.policyFor(lc.level); policy != policyInject {
+		return handleStripField(lc, fSet, policy)
 	}
 
 	// 使用 analyzeCallExpr 收集共享信息
-	isTarget, pos, _, _, _, expected, foundIndex := analyzeCallExpr(ce, sel, fSet, file, fns, modulePath, baseDir)
+	isTarget, pos, rel, _, _, expected, foundIndex := analyzeCallExpr(lc, fSet, sc, modulePath, baseDir)
 	if !isTarget {
 		return false, 0
 	}
 
+	// 同名字段不是由工具注入时不覆盖开发者记录的数据, -force 时按注入字段改写
+	if field := collidingField(lc); field != "" && !*forceFlg {
+		warnCollision(lc, rel, pos.Line, field)
+		return false, 0
+	}
+
 	// ellipsis 路径: 使用 append([]zap.Field{zap.String("fl", "...")}, expandedArg...) 包裹
-	if ce.Ellipsis.IsValid() {
-		return handleEllipsisInjection(ce, expected, pos)
+	if lc.isEllipsis() {
+		return handleEllipsisInjection(lc, expected, pos)
 	}
 
 	// 非 ellipsis 路径: 直接插入或更新参数
-	return handleNonEllipsisInsert(ce, expected, pos, foundIndex)
+	return handleNonEllipsisInsert(lc, expected, pos, foundIndex)
 }
 
 // handleDeleteField 处理删除字段逻辑, 返回是否修改以及修改所在的行号
-func handleDeleteField(ce *ast.CallExpr, fSet *token.FileSet) (bool, int) {
-	deleted := false
-
-	if ce.Ellipsis.IsValid() {
-		// ellipsis 调用: 检查展开参数是否被 append([]zap.Field{zap.String(delKey, ...)}, x...) 包裹, 解包还原
-		lastIdx := len(ce.Args) - 1
-		if _, _, origArg := findEllipsisFieldCall(ce.Args[lastIdx], *delFlg); origArg != nil {
-			ce.Args[lastIdx] = origArg
-			deleted = true
-		}
-	} else {
-		if idxDel := findExistingFieldIndex(ce, *delFlg); idxDel >= 0 && idxDel < len(ce.Args) {
-			ce.Args = append(ce.Args[:idxDel], ce.Args[idxDel+1:]...)
-			deleted = true
-		}
+func handleDeleteField(lc logCall, fSet *token.FileSet) (bool, int) {
+	if removeField(lc, *delFlg, false) {
+		pos := fSet.Position(lc.site)
+		return true, pos.Line
 	}
 
-	if deleted {
-		pos := fSet.Position(ce.Lparen)
+	return false, 0
+}
+
+// handleStripField 按级别策略移除 -field 指定的字段, 返回是否修改以及修改所在的行号。
+// policySkip 只移除由工具注入的字段, policyStrip 移除该键的任意字段
+func handleStripField(lc logCall, fSet *token.FileSet, policy string) (bool, int) {
+	if removeField(lc, *fieldFlg, policy == policySkip) {
+		pos := fSet.Position(lc.site)
 		return true, pos.Line
 	}
 
 	return false, 0
 }
 
+// removeField 从调用中移除键为 key 的字段, ownedOnly 为 true 时只移除由工具注入的字段, 返回是否发生了移除
+func removeField(lc logCall, key string, ownedOnly bool) bool {
+	if !hasRemovableField(lc, key, ownedOnly) {
+		return false
+	}
+
+	if lc.isEllipsis() {
+		// ellipsis 调用: 展开参数被 append([]zap.Field{zap.String(key, ...)}, x...) 包裹, 解包还原
+		lastIdx := len(lc.ce.Args) - 1
+		_, _, origArg := findEllipsisFieldCall(lc.b, lc.ce.Args[lastIdx], key)
+		lc.ce.Args[lastIdx] = origArg
+
+		return true
+	}
+
+	// 键值对形式占用两个参数, 一并移除; 调用链形式移除对应的方法调用
+	f, _ := findField(lc, key)
+	exprs := lc.fields()
+	lc.setFields(append(exprs[:f.index], exprs[f.index+f.width:]...))
+
+	return true
+}
+
+// hasRemovableField 判断调用中是否存在可由 removeField 移除的键为 key 的字段, 不修改 AST
+func hasRemovableField(lc logCall, key string, ownedOnly bool) bool {
+	if lc.isEllipsis() {
+		_, fieldCall, origArg := findEllipsisFieldCall(lc.b, lc.ce.Args[len(lc.ce.Args)-1], key)
+
+		return origArg != nil && (!ownedOnly || lc.b.isInjectedField(fieldCall))
+	}
+
+	f, ok := findField(lc, key)
+
+	return ok && (!ownedOnly || isOwnedField(lc, f))
+}
+
 // handleEllipsisInjection 处理 ellipsis 场景的字段注入, 返回是否修改以及修改所在的行号
-func handleEllipsisInjection(ce *ast.CallExpr, expected string, pos token.Position) (bool, int) {
+func handleEllipsisInjection(lc logCall, expected string, pos token.Position) (bool, int) {
+	ce := lc.ce
 	lastIdx := len(ce.Args) - 1
 	expandedArg := ce.Args[lastIdx]
 
 	// 检查是否已包裹: append([]zap.Field{zap.String("fl", "...")}, x...) → 更新值
-	if _, zapCall, _ := findEllipsisFieldCall(expandedArg, *fieldFlg); zapCall != nil {
-		if len(zapCall.Args) >= 2 {
-			oldPos := zapCall.Args[1].Pos()
-			zapCall.Args[1] = &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(expected), ValuePos: oldPos}
+	if _, fieldCall, _ := findEllipsisFieldCall(lc.b, expandedArg, *fieldFlg); fieldCall != nil {
+		// -force 覆盖冲突字段时构造函数同样改写为 String
+		if sel, ok := fieldCall.Fun.(*ast.SelectorExpr); ok {
+			sel.Sel = &ast.Ident{Name: lc.b.fieldFunc, NamePos: sel.Sel.NamePos}
+		}
+
+		if len(fieldCall.Args) >= 2 {
+			fieldCall.Args = []ast.Expr{fieldCall.Args[0], makeValueExpr(expected, fieldCall.Args[1].Pos())}
 		}
 
 		return true, pos.Line
 	}
 
 	// 未包裹: 用 append([]zap.Field{newArg}, expandedArg...) 包裹, 注入字段在切片第一位
-	newArg := makeZapStringArg(expected)
-	wrapExpr := makeEllipsisAppend(newArg, expandedArg)
+	newArg := lc.b.makeFieldArg(expected)
+	wrapExpr := makeEllipsisAppend(newArg, expandedArg, lc.sliceType)
 	ce.Args[lastIdx] = wrapExpr
 
 	return true, pos.Line
 }
 
-// handleNonEllipsisInsert 处理非 ellipsis 场景的字段插入或更新, 返回是否修改以及修改所在的行号
-func handleNonEllipsisInsert(ce *ast.CallExpr, expected string, pos token.Position, foundIndex int) (bool, int) {
-	newArg := makeZapStringArg(expected)
+// handleNonEllipsisInsert 处理非 ellipsis 场景的字段插入或更新, 返回是否修改以及修改所在的行号。
+// foundIndex 为已存在字段在 lc.fields() 中的索引, 未找到为 -1
+func handleNonEllipsisInsert(lc logCall, expected string, pos token.Position, foundIndex int) (bool, int) {
+	exprs := lc.fields()
+
+	// 已存在的键值对字段: 保留键值对形式, 只更新值
+	if foundIndex >= 0 && foundIndex+1 < len(exprs) && lc.kvPairs && isStringLit(exprs[foundIndex]) {
+		exprs[foundIndex+1] = makeValueExpr(expected, exprs[foundIndex+1].Pos())
+		lc.setFields(exprs)
+
+		return true, pos.Line
+	}
+
+	// 已存在的链式字段: 原地改写为 .Str(key, "<expected>"), 保留其在调用链中的位置与换行
+	if foundIndex >= 0 && lc.chain != nil {
+		rewriteChainField(lc.b, lc.chain.links[foundIndex], expected)
+		return true, pos.Line
+	}
+
+	newArg := lc.b.makeFieldArg(expected)
 
 	// 设置新节点位置, 防止 go/printer 将相邻注释吸入参数内部
-	setExprPos(newArg, ce.Lparen)
+	setExprPos(newArg, lc.anchor())
 
 	if foundIndex >= 0 {
-		ce.Args[foundIndex] = newArg
+		exprs[foundIndex] = newArg
+		lc.setFields(exprs)
+
 		return true, pos.Line
 	}
 
-	// 计算插入索引: position 基于 field 参数列表(跳过第一个 msg 参数)
+	// 计算插入索引: position 基于 field 参数列表(跳过 msg 等非字段参数)
 	// position=0 表示插入到第一个 field 之前(即 msg 之后), 默认(-1)等同于 0
-	insertArgs(ce, newArg)
+	insertArgs(lc, newArg)
 
-	// 如果要求按字母排序 zap 字段, 则对参数列表重新排序
+	// 如果要求按字母排序字段, 则对参数列表重新排序
 	if *sortFlg {
-		sortZapFields(ce)
+		sortFields(lc)
 	}
 
 	return true, pos.Line
 }
 
-// insertArgs 将 newArg 插入到 ce.Args 中由 positionFlg 决定的位置
-func insertArgs(ce *ast.CallExpr, newArg ast.Expr) {
-	old := ce.Args
-
-	insertIdx := 1 // AST 索引 1 = msg 之后
-
-	if *positionFlg >= 0 {
-		// field 索引 + 1(跳过 msg) = AST 索引
-		astIdx := *positionFlg + 1
-		if astIdx > len(old) {
-			insertIdx = len(old)
-		} else {
-			insertIdx = astIdx
-		}
-	}
+// rewriteChainField 将调用链上的字段方法调用改写为 .Str(key, "<v>"), 新参数沿用原参数的位置
+func rewriteChainField(b *backend, link *ast.CallExpr, v string) {
+	sel := link.Fun.(*ast.SelectorExpr)
+	sel.Sel = &ast.Ident{Name: b.fieldFunc, NamePos: sel.Sel.NamePos}
 
-	// 插入 newArg 到 insertIdx
-	switch {
-	case insertIdx <= 0:
-		newArgs := make([]ast.Expr, 0, len(old)+1)
-		newArgs = append(newArgs, newArg)
-		newArgs = append(newArgs, old...)
-		ce.Args = newArgs
-	case insertIdx >= len(old):
-		old = append(old, newArg)
-		ce.Args = old
-	default:
-		newArgs := make([]ast.Expr, 0, len(old)+1)
-		newArgs = append(newArgs, old[:insertIdx]...)
-		newArgs = append(newArgs, newArg)
-		newArgs = append(newArgs, old[insertIdx:]...)
-		ce.Args = newArgs
+	keyPos, valPos := link.Lparen, link.Lparen
+	if len(link.Args) > 0 {
+		keyPos = link.Args[0].Pos()
+		valPos = keyPos
 	}
-}
 
-// makeZapStringArg 构造 zap.String(...) 表达式
-func makeZapStringArg(v string) ast.Expr {
-	return &ast.CallExpr{
-		Fun: &ast.SelectorExpr{X: ast.NewIdent(zapIdent), Sel: ast.NewIdent("String")},
-		Args: []ast.Expr{
-			&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(*fieldFlg)},
-			&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(v)},
-		},
+	if len(link.Args) > 1 {
+		valPos = link.Args[1].Pos()
 	}
-}
 
-// findExistingFieldIndex 在已有参数中查找是否已经包含目标字段, 返回真实索引或 -1
-func findExistingFieldIndex(ce *ast.CallExpr, key string) int {
-	// 遍历传入参数(跳过第一个参数), 使用短路 continue 降低嵌套层级
-	for i, a := range ce.Args[1:] {
-		if matchesZapFieldKey(a, key) {
-			return i + 1
-		}
+	link.Args = []ast.Expr{
+		&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(*fieldFlg), ValuePos: keyPos},
+		makeValueExpr(v, valPos),
 	}
-
-	return -1
 }
 
-// matchesZapFieldKey 判断参数表达式是否为 zap.<Method>(key, ...) 调用且第一个参数为字符串字面量等于 key
-func matchesZapFieldKey(a ast.Expr, key string) bool {
-	call, ok := a.(*ast.CallExpr)
-	if !ok {
-		return false
-	}
-
-	funSel, ok := call.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return false
-	}
+// insertArgs 将 newArg 插入到字段列表中由 positionFlg 决定的位置。
+// positionFlg 为字段索引, 键值对形式的字段占用两个参数但只计为一个字段
+func insertArgs(lc logCall, newArg ast.Expr) {
+	old := lc.fields()
 
-	id, ok := funSel.X.(*ast.Ident)
-	if !ok || id.Name != zapIdent {
-		return false
-	}
+	insertIdx := 0 // 默认插入到第一个 field 之前
 
-	// 放宽匹配: 只要是 zap.<Something>(<key>, ...) 且第一个参数为字符串字面量且等于 key, 就视为匹配
-	if len(call.Args) == 0 {
-		return false
+	if *positionFlg >= 0 {
+		// 第 positionFlg 个字段的索引, 超出字段个数时追加到末尾
+		fields := parseFields(lc)
+		if *positionFlg < len(fields) {
+			insertIdx = fields[*positionFlg].index
+		} else {
+			insertIdx = len(old)
+		}
 	}
 
-	lit := parseLitKey(call.Args[0])
+	newArgs := make([]ast.Expr, 0, len(old)+1)
+	newArgs = append(newArgs, old[:insertIdx]...)
+	newArgs = append(newArgs, newArg)
+	newArgs = append(newArgs, old[insertIdx:]...)
 
-	return lit == key
+	lc.setFields(newArgs)
 }
 
-// makeEllipsisAppend 构造 append([]zap.Field{zapStringArg}, expandedArg...) 表达式。
+// makeEllipsisAppend 构造 append([]<sliceType>{fieldArg}, expandedArg...) 表达式, 例如 append([]zap.Field{...}, fields...)。
 // 内层 append 调用设置 Ellipsis 以确保第二个参数被展开。
 // 所有新建 AST 节点的位置都设置为 expandedArg.Pos(), 防止 go/printer 将函数间注释吸入表达式内部。
-func makeEllipsisAppend(zapStringArg ast.Expr, expandedArg ast.Expr) *ast.CallExpr {
+func makeEllipsisAppend(fieldArg ast.Expr, expandedArg ast.Expr, sliceType string) *ast.CallExpr {
 	pos := expandedArg.Pos()
 
-	// 先递归设置 zapStringArg 的位置(它是新建的节点)
-	setExprPos(zapStringArg, pos)
+	// 先递归设置 fieldArg 的位置(它是新建的节点)
+	setExprPos(fieldArg, pos)
 
 	headSlice := &ast.CompositeLit{
 		Type: &ast.ArrayType{
 			Lbrack: pos,
-			Elt: &ast.SelectorExpr{
-				X:   &ast.Ident{Name: zapIdent, NamePos: pos},
-				Sel: &ast.Ident{Name: "Field", NamePos: pos},
-			},
+			Elt:    makeTypeExpr(sliceType, pos),
 		},
-		Elts:   []ast.Expr{zapStringArg},
+		Elts:   []ast.Expr{fieldArg},
 		Lbrace: pos,
 		Rbrace: pos,
 	}
@@ -326,6 +357,18 @@ func makeEllipsisAppend(zapStringArg ast.Expr, expandedArg ast.Expr) *ast.CallEx
 	}
 }
 
+// makeTypeExpr 将 zap.Field、any 形式的类型名构造为类型表达式
+func makeTypeExpr(name string, pos token.Pos) ast.Expr {
+	if pkg, typ, ok := strings.Cut(name, "."); ok {
+		return &ast.SelectorExpr{
+			X:   &ast.Ident{Name: pkg, NamePos: pos},
+			Sel: &ast.Ident{Name: typ, NamePos: pos},
+		}
+	}
+
+	return &ast.Ident{Name: name, NamePos: pos}
+}
+
 // setExprPos 递归设置 AST 表达式树中所有节点的位置。
 // 用于确保新建的 AST 节点具有正确位置, 避免 go/printer 在格式化时将相邻注释错误地插入到表达式内部。
 func setExprPos(e ast.Expr, pos token.Pos) {
@@ -388,9 +431,9 @@ func setArrayTypePos(v *ast.ArrayType, pos token.Pos) {
 }
 
 // findEllipsisFieldCall 检查 ellipsis 展开参数是否已被 append([]zap.Field{zap.String(key, val)}, original...) 包裹。
-// 如果匹配, 返回 append 调用、内部的 zap.String 调用、以及被包裹的原始参数(用于解包)。
+// 如果匹配, 返回 append 调用、内部的字段构造调用、以及被包裹的原始参数(用于解包)。
 // 如果不匹配, 返回 nil, nil, nil。
-func findEllipsisFieldCall(expandedArg ast.Expr, key string) (*ast.CallExpr, *ast.CallExpr, ast.Expr) {
+func findEllipsisFieldCall(b *backend, expandedArg ast.Expr, key string) (*ast.CallExpr, *ast.CallExpr, ast.Expr) {
 	appendCall, ok := expandedArg.(*ast.CallExpr)
 	if !ok {
 		return nil, nil, nil
@@ -406,7 +449,7 @@ func findEllipsisFieldCall(expandedArg ast.Expr, key string) (*ast.CallExpr, *as
 	}
 
 	// 新模式: append([]zap.Field{zap.String(key, val)}, original...)
-	// 第一个参数是 CompositeLit []zap.Field{...}, 包含一个 zap.String 调用
+	// 第一个参数是 CompositeLit []zap.Field{...}, 包含一个字段构造调用
 	compLit, ok := appendCall.Args[0].(*ast.CompositeLit)
 	if !ok {
 		return nil, nil, nil
@@ -416,102 +459,59 @@ func findEllipsisFieldCall(expandedArg ast.Expr, key string) (*ast.CallExpr, *as
 		return nil, nil, nil
 	}
 
-	zapCall, ok := compLit.Elts[0].(*ast.CallExpr)
-	if !ok {
-		return nil, nil, nil
-	}
-
-	funSel, ok := zapCall.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return nil, nil, nil
-	}
-
-	id, ok := funSel.X.(*ast.Ident)
-	if !ok || id.Name != zapIdent {
-		return nil, nil, nil
-	}
-
 	// 放宽匹配: 只要是 zap.<Method>(<key>, ...) 且第一个参数为字符串字面量等于 key 即可
-	if len(zapCall.Args) < 1 {
+	if !b.matchesFieldKey(compLit.Elts[0], key) {
 		return nil, nil, nil
 	}
 
-	bl, ok := zapCall.Args[0].(*ast.BasicLit)
-	if !ok {
-		return nil, nil, nil
-	}
-
-	var lit string
-	if s, err := strconv.Unquote(bl.Value); err == nil {
-		lit = s
-	} else {
-		lit = strings.Trim(bl.Value, "\"")
-	}
-
-	if lit != key {
-		return nil, nil, nil
-	}
+	fieldCall, _ := compLit.Elts[0].(*ast.CallExpr)
 
 	// 原始被展开的参数是第二个 append 参数
-	return appendCall, zapCall, appendCall.Args[1]
-}
-
-// isZapChain 检查 expr 是否包含 zap.L() 的调用链, 例如 zap.L().With(...).Info 中的 receiver
-func isZapChain(expr ast.Expr) bool {
-	switch v := expr.(type) {
-	case *ast.CallExpr:
-		// 函数可能是 SelectorExpr (zap.L()) or another call
-		if sel, ok := v.Fun.(*ast.SelectorExpr); ok {
-			if ident, ok := sel.X.(*ast.Ident); ok {
-				if ident.Name == zapIdent && sel.Sel.Name == "L" {
-					return true
-				}
-			}
-		}
-
-		return isZapChain(v.Fun)
-	case *ast.SelectorExpr:
-		return isZapChain(v.X)
-	case *ast.Ident:
-		return false
-	default:
-		return false
-	}
+	return appendCall, fieldCall, appendCall.Args[1]
 }
 
 // analyzeCallExpr 提取 handleCallExpr 与 verifyCallExpr 共享的检查与信息收集逻辑。
-// 返回: isTarget(是否为目标 zap 日志调用), pos(左括号位置), rel(相对路径), funcName, pkgName, expected(期望注入字符串), foundIndex(在 ce.Args 中的真实索引, 未找到返回 -1)
+// 返回: isTarget(是否为注入目标), pos(调用位置, Check 形式为 Check 调用的左括号), rel(相对路径), funcName, pkgName, expected(期望注入字符串), foundIndex(在 lc.fields() 中的索引, 未找到返回 -1)
 func analyzeCallExpr(
-	ce *ast.CallExpr,
-	sel *ast.SelectorExpr,
+	lc logCall,
 	fSet *token.FileSet,
-	file *ast.File,
-	fns []fnRange,
+	sc *fileScope,
 	modulePath, baseDir string,
 ) (bool, token.Position, string, string, string, string, int) {
-	method := sel.Sel.Name
-	if !logMethods[method] {
+	// 级别策略为 skip/strip 的调用不是注入目标
+	if sc.policyFor(lc.level) != policyInject {
 		return false, token.Position{}, "", "", "", "", -1
 	}
 
-	if !isZapChain(sel.X) {
-		return false, token.Position{}, "", "", "", "", -1
+	pos, rel, funcName, pkgName := siteInfo(lc, fSet, sc, baseDir)
+
+	expected := buildInjectedValue(rel, pos, funcName, pkgName, modulePath)
+
+	// -locs 模式下调用处引用位置常量, 期望值为常量名
+	if sc.locIdents != nil {
+		expected = sc.locIdents[lc.ce]
 	}
 
-	if len(ce.Args) == 0 {
-		return false, token.Position{}, "", "", "", "", -1
+	foundIndex := -1
+	if f, ok := findField(lc, *fieldFlg); ok {
+		foundIndex = f.index
 	}
 
-	pos := fSet.Position(ce.Lparen)
+	return true, pos, rel, funcName, pkgName, expected, foundIndex
+}
 
-	// 使用 relPath 计算相对于仓库根的路径
-	rel := relPath(pos.Filename, baseDir)
+// siteInfo 返回调用位置(Check 形式为 Check 调用的左括号)、相对仓库根的路径以及所在的函数名与包名
+func siteInfo(lc logCall, fSet *token.FileSet, sc *fileScope, baseDir string) (token.Position, string, string, string) {
+	pos := fSet.Position(lc.site)
 
-	callOffset := fSet.Position(ce.Pos()).Offset
+	// 计算注入值中的相对路径, 基准目录由 -rel-to 决定
+	rel := siteRel(pos.Filename, baseDir)
+
+	callOffset := pos.Offset
 	funcName := ""
-	pkgName := file.Name.Name
+	pkgName := sc.file.Name.Name
 
-	for _, fr := range fns {
+	for _, fr := range sc.fns {
 		if callOffset >= fr.start && callOffset <= fr.end {
 			funcName = fr.name
 			pkgName = fr.pkg
@@ -520,11 +520,34 @@ func analyzeCallExpr(
 		}
 	}
 
-	expected := buildInjectedValue(rel, pos, funcName, pkgName, modulePath)
+	return pos, rel, funcName, pkgName
+}
 
-	foundIndex := findExistingFieldIndex(ce, *fieldFlg)
+// maxFixRounds 行号修正与格式化交替执行的最大轮数
+const maxFixRounds = 8
 
-	return true, pos, rel, funcName, pkgName, expected, foundIndex
+// formatSource 使用 go/format 格式化输出, 保证与 gofmt 一致; 格式化失败时原样返回
+func formatSource(out string) string {
+	formatted, err := format.Source([]byte(out))
+	if err != nil {
+		return out
+	}
+
+	return string(formatted)
+}
+
+// fixLineNumbers 交替执行行号修正与格式化直到输出不再变化, 保证写回后立即 -verify 不会报告 mismatch
+func fixLineNumbers(out, path, modulePath, baseDir string) (string, error) {
+	for range maxFixRounds {
+		next := formatSource(correctLineNumbers(out, path, modulePath, baseDir))
+		if next == out {
+			return out, nil
+		}
+
+		out = next
+	}
+
+	return "", fmt.Errorf("%s: line numbers did not converge after %d rounds", path, maxFixRounds)
 }
 
 // correctLineNumbers 对 printer 输出进行二次修正:
@@ -539,13 +562,12 @@ func correctLineNumbers(output string, path string, modulePath string, baseDir s
 		return output
 	}
 
-	if !hasZapImport(file2) {
+	sc := newFileScope(file2, fSet2)
+	if len(sc.backends) == 0 {
 		return output
 	}
 
-	fns := collectFuncRanges(file2, fSet2)
-
-	edits := collectLineEdits(file2, fSet2, fns, modulePath, baseDir)
+	edits := collectLineEdits(sc, fSet2, modulePath, baseDir)
 
 	if len(edits) == 0 {
 		return output
@@ -562,26 +584,26 @@ type lineEdit struct {
 }
 
 // collectLineEdits 遍历 AST 收集所有需要修正行号的编辑项
-func collectLineEdits(file2 *ast.File, fSet2 *token.FileSet, fns []fnRange, modulePath, baseDir string) []lineEdit {
+func collectLineEdits(sc *fileScope, fSet2 *token.FileSet, modulePath, baseDir string) []lineEdit {
 	var edits []lineEdit
 
-	ast.Inspect(file2, func(n ast.Node) bool {
+	ast.Inspect(sc.file, func(n ast.Node) bool {
 		ce, ok := n.(*ast.CallExpr)
 		if !ok {
 			return true
 		}
 
-		sel, ok := ce.Fun.(*ast.SelectorExpr)
+		lc, ok := matchLogCall(ce, sc)
 		if !ok {
 			return true
 		}
 
-		isTarget, _, _, _, _, expected2, _ := analyzeCallExpr(ce, sel, fSet2, file2, fns, modulePath, baseDir)
+		isTarget, _, _, _, _, expected2, _ := analyzeCallExpr(lc, fSet2, sc, modulePath, baseDir)
 		if !isTarget {
 			return true
 		}
 
-		bl := findInjectedFieldLit(ce)
+		bl := findInjectedFieldLit(lc)
 		if bl == nil {
 			return true
 		}
@@ -609,13 +631,15 @@ func collectLineEdits(file2 *ast.File, fSet2 *token.FileSet, fns []fnRange, modu
 }
 
 // findInjectedFieldLit 在调用表达式中查找注入字段的值 BasicLit
-func findInjectedFieldLit(ce *ast.CallExpr) *ast.BasicLit {
-	if ce.Ellipsis.IsValid() {
+func findInjectedFieldLit(lc logCall) *ast.BasicLit {
+	ce := lc.ce
+
+	if lc.isEllipsis() {
 		lastIdx := len(ce.Args) - 1
-		_, zapCall, _ := findEllipsisFieldCall(ce.Args[lastIdx], *fieldFlg)
+		_, fieldCall, _ := findEllipsisFieldCall(lc.b, ce.Args[lastIdx], *fieldFlg)
 
-		if zapCall != nil && len(zapCall.Args) >= 2 {
-			if b, ok := zapCall.Args[1].(*ast.BasicLit); ok {
+		if fieldCall != nil && len(fieldCall.Args) >= 2 && lc.b.isInjectedField(fieldCall) {
+			if b, ok := fieldCall.Args[1].(*ast.BasicLit); ok {
 				return b
 			}
 		}
@@ -623,17 +647,13 @@ func findInjectedFieldLit(ce *ast.CallExpr) *ast.BasicLit {
 		return nil
 	}
 
-	idx := findExistingFieldIndex(ce, *fieldFlg)
-	if idx < 0 {
-		return nil
-	}
-
-	call, ok := ce.Args[idx].(*ast.CallExpr)
-	if !ok || len(call.Args) < 2 {
+	// 冲突字段不是由工具注入的, 不修正其值
+	f, ok := findField(lc, *fieldFlg)
+	if !ok || !isOwnedField(lc, f) {
 		return nil
 	}
 
-	if b, ok := call.Args[1].(*ast.BasicLit); ok {
+	if b, ok := f.value.(*ast.BasicLit); ok {
 		return b
 	}
 
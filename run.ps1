@@ -279,7 +279,7 @@ function getExePath {
 function testdataPreview {
     $exe = getExePath
     Write-Host "预览所有 inputs 文件的默认注入结果 (-field fl)..." -ForegroundColor Cyan
-    & $exe -path testdata/inputs -field fl
+    & $exe -path testdata/inputs -field fl -rel-to path
     Write-Host "✅ 预览完毕"
 }
 
@@ -293,7 +293,7 @@ function testdataWriteAndDiff {
     Write-Host "已复制 inputs 到临时目录: $tmpDir" -ForegroundColor Cyan
 
     # 执行写入
-    & $exe -path $tmpDir -field fl -write
+    & $exe -path $tmpDir -field fl -rel-to path -write
 
     # 对比每个文件
     Write-Host ""
@@ -333,11 +333,11 @@ function testdataWriteAndDiff {
 function testdataVerify {
     $exe = getExePath
     Write-Host "验证 expected 目录中已注入文件的正确性..." -ForegroundColor Cyan
-    & $exe -path testdata/expected -field fl -verify -exclude "with_func,sort,del,position"
+    & $exe -path testdata/expected -field fl -rel-to path -verify -exclude "with_func,sort,del,position"
 
     Write-Host ""
     Write-Host "验证 mismatch 场景 (应报告 mismatch)..." -ForegroundColor Cyan
-    & $exe -path testdata/inputs/mismatch.go -field fl -verify
+    & $exe -path testdata/inputs/mismatch.go -field fl -rel-to path -verify
     Write-Host "✅ 验证完毕"
 }
 
@@ -361,7 +361,7 @@ function testdataDelFieldsSlice {
 function testdataSort {
     $exe = getExePath
     Write-Host "预览排序字段 (-sort)..." -ForegroundColor Cyan
-    & $exe -path testdata/inputs/sort_fields.go -field fl -sort
+    & $exe -path testdata/inputs/sort_fields.go -field fl -rel-to path -sort
     Write-Host "✅ 预览完毕"
 }
 
@@ -369,7 +369,7 @@ function testdataSort {
 function testdataPosition {
     $exe = getExePath
     Write-Host "预览位置插入 (-position 0, 插入到参数列表最前)..." -ForegroundColor Cyan
-    & $exe -path testdata/inputs/position_insert.go -field fl -position 0
+    & $exe -path testdata/inputs/position_insert.go -field fl -rel-to path -position 0
     Write-Host "✅ 预览完毕"
 }
 
@@ -377,7 +377,7 @@ function testdataPosition {
 function testdataWithFunc {
     $exe = getExePath
     Write-Host "预览函数名注入 (-with-func)..." -ForegroundColor Cyan
-    & $exe -path testdata/inputs/with_func_name.go -field fl -with-func
+    & $exe -path testdata/inputs/with_func_name.go -field fl -rel-to path -with-func
     Write-Host "✅ 预览完毕"
 }
 
@@ -400,17 +400,17 @@ function testdataRegenExpected {
     # 2. 默认场景: 复制 inputs 到 expected 并执行 -field fl -write
     Copy-Item -Path "testdata\inputs\*" -Destination "testdata\expected\" -Recurse
     Write-Host "  生成默认场景 (-field fl)..." -ForegroundColor Yellow
-    & $exe -path testdata/expected -field fl -write
+    & $exe -path testdata/expected -field fl -rel-to path -write
 
     # 3. with_func 场景
     Copy-Item "testdata\inputs\with_func_name.go" "testdata\expected\with_func\with_func_name.go" -Force
     Write-Host "  生成 with_func 场景 (-with-func)..." -ForegroundColor Yellow
-    & $exe -path testdata/expected/with_func -field fl -with-func -write
+    & $exe -path testdata/expected/with_func -field fl -rel-to path -with-func -write
 
     # 4. sort 场景
     Copy-Item "testdata\inputs\sort_fields.go" "testdata\expected\sort\sort_fields.go" -Force
     Write-Host "  生成 sort 场景 (-sort)..." -ForegroundColor Yellow
-    & $exe -path testdata/expected/sort -field fl -sort -write
+    & $exe -path testdata/expected/sort -field fl -rel-to path -sort -write
 
     # 5. del 场景
     Copy-Item "testdata\inputs\del_target.go" "testdata\expected\del\del_target.go" -Force
@@ -423,7 +423,7 @@ function testdataRegenExpected {
     # 6. position 场景
     Copy-Item "testdata\inputs\position_insert.go" "testdata\expected\position\position_insert.go" -Force
     Write-Host "  生成 position 场景 (-position 0)..." -ForegroundColor Yellow
-    & $exe -path testdata/expected/position -field fl -position 0 -write
+    & $exe -path testdata/expected/position -field fl -rel-to path -position 0 -write
 
     Write-Host "✅ 所有 expected 文件已重新生成"
 }
@@ -0,0 +1,66 @@
+//
+// FilePath    : zap-smap\runtime\caller.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 将注入字段提升为 zap 的 caller
+//
+
+package runtime
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// callerCore 将日志条目中的注入字段移除, 并以其位置设置 Entry.Caller
+type callerCore struct {
+	zapcore.Core
+	key string
+}
+
+// NewCallerCore 包装 core: 条目带有键为 key 的注入字段时, 移除该字段并以其位置设置 Entry.Caller,
+// 编码器因此输出 "caller":"order/order.go:42", 配置了 FunctionKey 时输出 -with-func 注入的函数名。
+// 不带注入字段或字段值无法解析(例如 -token 令牌)的条目保持不变, 仍使用运行时的 caller。
+// key 为空时使用 DefaultKey
+func NewCallerCore(core zapcore.Core, key string) zapcore.Core {
+	if key == "" {
+		key = DefaultKey
+	}
+
+	return &callerCore{Core: core, key: key}
+}
+
+// WithCaller 返回以 NewCallerCore 包装 logger core 的 zap.Option
+func WithCaller(key string) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewCallerCore(core, key)
+	})
+}
+
+// With 实现 zapcore.Core
+func (c *callerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &callerCore{Core: c.Core.With(fields), key: c.key}
+}
+
+// Check 实现 zapcore.Core, 由被包装的 core 决定是否接收条目
+func (c *callerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checkInner(c.Core, ent, ce, c.write)
+}
+
+// Write 实现 zapcore.Core
+func (c *callerCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.write(ent, fields, c.Core.Write)
+}
+
+// write 以注入字段设置 Entry.Caller 并移除该字段后写入 next
+func (c *callerCore) write(ent zapcore.Entry, fields []zapcore.Field, next func(zapcore.Entry, []zapcore.Field) error) error {
+	if i, v := findField(fields, c.key); i >= 0 {
+		if site, ok := ParseSite(v); ok {
+			ent.Caller = zapcore.EntryCaller{Defined: true, File: site.File, Line: site.Line, Function: site.Func}
+			fields = withoutField(fields, i)
+		}
+	}
+
+	return next(ent, fields)
+}
@@ -0,0 +1,137 @@
+//
+// FilePath    : zap-smap\runtime\caller_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : caller 提升单测
+//
+
+package runtime
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestParseSite 测试注入值的解析
+func TestParseSite(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Site
+		ok   bool
+	}{
+		{"order/order.go:42", Site{File: "order/order.go", Line: 42}, true},
+		{"order/order.go:42 | app/order.Create", Site{File: "order/order.go", Line: 42, Func: "app/order.Create"}, true},
+		{"3f9a0c1d2e4b5a67", Site{}, false},
+		{"a.go:0", Site{}, false},
+		{":7", Site{}, false},
+	}
+
+	for _, c := range cases {
+		got, ok := ParseSite(c.in)
+		if ok != c.ok || got != c.want {
+			t.Fatalf("ParseSite(%q) = %+v, %v; want %+v, %v", c.in, got, ok, c.want, c.ok)
+		}
+
+		if ok && got.String() != c.in {
+			t.Fatalf("Site.String() = %q, want %q", got.String(), c.in)
+		}
+	}
+}
+
+// TestCallerCore_PromotesField 测试注入字段被移除并设置为 Entry.Caller
+func TestCallerCore_PromotesField(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core, zap.AddCaller(), WithCaller(""))
+
+	fields := []zap.Field{zap.String("fl", "order/order.go:42 | app/order.Create"), zap.Int("n", 1)}
+	logger.Info("created", fields...)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if !e.Caller.Defined || e.Caller.File != "order/order.go" || e.Caller.Line != 42 || e.Caller.Function != "app/order.Create" {
+		t.Fatalf("unexpected caller: %+v", e.Caller)
+	}
+
+	if _, ok := e.ContextMap()["fl"]; ok || e.ContextMap()["n"] != int64(1) {
+		t.Fatalf("expected fl removed and other fields kept, got %v", e.ContextMap())
+	}
+
+	// 调用方的字段切片不被修改
+	if fields[0].Key != "fl" || len(fields) != 2 {
+		t.Fatalf("expected caller's fields untouched, got %+v", fields)
+	}
+}
+
+// TestCallerCore_FallbackAndCustomKey 测试无注入字段或值无法解析时使用运行时 caller, 以及自定义字段键
+func TestCallerCore_FallbackAndCustomKey(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core, zap.AddCaller(), WithCaller("loc")).With(zap.String("svc", "order"))
+
+	logger.Info("plain")
+	logger.Info("token", zap.String("loc", "3f9a0c1d2e4b5a67"))
+	logger.Info("custom", zap.String("loc", "a.go:7"))
+	logger.Debug("other key", zap.String("fl", "a.go:8"))
+
+	entries := logs.All()
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+
+	for _, i := range []int{0, 1, 3} {
+		if !strings.HasSuffix(entries[i].Caller.File, "caller_test.go") {
+			t.Fatalf("entry %d: expected runtime caller, got %+v", i, entries[i].Caller)
+		}
+	}
+
+	if entries[1].ContextMap()["loc"] != "3f9a0c1d2e4b5a67" {
+		t.Fatalf("expected unparsable value kept, got %v", entries[1].ContextMap())
+	}
+
+	if entries[2].Caller.File != "a.go" || entries[2].Caller.Line != 7 || entries[2].ContextMap()["svc"] != "order" {
+		t.Fatalf("unexpected custom key entry: %+v %v", entries[2].Caller, entries[2].ContextMap())
+	}
+}
+
+// TestCallerCore_JSONEncoder 测试 JSON 编码器输出提升后的 caller 与函数名
+func TestCallerCore_JSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = ""
+	cfg.FunctionKey = "func"
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(cfg), zapcore.AddSync(&buf), zapcore.InfoLevel)
+	logger := zap.New(NewCallerCore(core, ""), zap.AddCaller())
+
+	logger.Info("created", zap.String("fl", "order/order.go:42 | app/order.Create"))
+
+	want := `{"level":"info","caller":"order/order.go:42","func":"app/order.Create","msg":"created"}` + "\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// TestCallerCore_TeeLevels 测试包装 zapcore.NewTee 时每个分支仍按自己的级别过滤条目
+func TestCallerCore_TeeLevels(t *testing.T) {
+	tee, infoLogs, errorLogs := levelTee()
+	logger := zap.New(tee, WithCaller(""))
+
+	logger.Info("info", zap.String("fl", "a.go:1"))
+	logger.Error("error", zap.String("fl", "b.go:2"))
+
+	assertTeeLevels(t, infoLogs, errorLogs)
+
+	if e := errorLogs.All()[0]; e.Caller.File != "b.go" || e.Caller.Line != 2 || len(e.Context) != 0 {
+		t.Fatalf("expected caller promoted on error branch, got %+v", e)
+	}
+}
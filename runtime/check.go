@@ -0,0 +1,92 @@
+//
+// FilePath    : zap-smap\runtime\check.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 包装 core 的 Check 委托, 保证被包装的 core 按自己的级别过滤条目
+//
+
+package runtime
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// writeFunc 包装 core 的写出逻辑: 处理条目与字段后以 next 写入被包装的 core, 不调用 next 表示丢弃条目
+type writeFunc func(ent zapcore.Entry, fields []zapcore.Field, next func(zapcore.Entry, []zapcore.Field) error) error
+
+// checkInner 以被包装 core 的 Check 决定由哪些内层 core 接收条目(例如 zapcore.NewTee 的每个分支按自己的级别判断),
+// 有内层 core 接收时向 ce 登记以 write 处理条目的 core, write 的 next 只写入这些内层 core
+func checkInner(inner zapcore.Core, ent zapcore.Entry, ce *zapcore.CheckedEntry, write writeFunc) *zapcore.CheckedEntry {
+	down := inner.Check(ent, nil)
+	if down == nil {
+		return ce
+	}
+
+	return ce.AddCore(ent, &checkedCore{Core: inner, down: down, write: write})
+}
+
+// checkedCore 登记到外层 CheckedEntry 的 core, 持有被包装 core 的 Check 结果
+type checkedCore struct {
+	zapcore.Core
+	down  *zapcore.CheckedEntry
+	write writeFunc
+}
+
+// Write 实现 zapcore.Core
+func (c *checkedCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.write(ent, fields, func(ent zapcore.Entry, fields []zapcore.Field) error {
+		return writeChecked(c.down, ent, fields)
+	})
+}
+
+// writeChecked 将条目写入 down 中接受它的内层 core, 返回内层 core 的写入错误。down 写入后归还 zap 的对象池, 不能再次使用
+func writeChecked(down *zapcore.CheckedEntry, ent zapcore.Entry, fields []zapcore.Field) error {
+	var errOut errorOutput
+
+	down.Entry = ent
+	down.ErrorOutput = &errOut
+	down.Write(fields...)
+
+	return errOut.err()
+}
+
+// errorOutput 收集 CheckedEntry.Write 输出的写入错误
+type errorOutput struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+// Write 实现 zapcore.WriteSyncer
+func (o *errorOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Write(p)
+}
+
+// Sync 实现 zapcore.WriteSyncer
+func (o *errorOutput) Sync() error {
+	return nil
+}
+
+// err 返回收集到的写入错误, 去掉 CheckedEntry 添加的 "<time> write error: " 前缀
+func (o *errorOutput) err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg := strings.TrimSpace(o.buf.String())
+	if msg == "" {
+		return nil
+	}
+
+	if _, after, ok := strings.Cut(msg, " write error: "); ok {
+		msg = after
+	}
+
+	return errors.New(msg)
+}
@@ -0,0 +1,92 @@
+//
+// FilePath    : zap-smap\runtime\check_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 包装 core 的 Check 委托单测
+//
+
+package runtime
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// levelTee 返回 Info 与 Error 两个级别的观察 core 组成的 zapcore.NewTee
+func levelTee() (zapcore.Core, *observer.ObservedLogs, *observer.ObservedLogs) {
+	infoCore, infoLogs := observer.New(zapcore.InfoLevel)
+	errorCore, errorLogs := observer.New(zapcore.ErrorLevel)
+
+	return zapcore.NewTee(infoCore, errorCore), infoLogs, errorLogs
+}
+
+// assertTeeLevels 断言 Info 条目只写入 Info 分支, Error 条目写入两个分支
+func assertTeeLevels(t *testing.T, infoLogs, errorLogs *observer.ObservedLogs) {
+	t.Helper()
+
+	if infoLogs.FilterMessage("info").Len() != 1 || infoLogs.FilterMessage("error").Len() != 1 {
+		t.Fatalf("unexpected info branch entries: %v", messages(infoLogs))
+	}
+
+	if errorLogs.FilterMessage("info").Len() != 0 || errorLogs.FilterMessage("error").Len() != 1 {
+		t.Fatalf("expected error branch to receive only error entries, got %v", messages(errorLogs))
+	}
+}
+
+// failingCore 写入总是失败的 core
+type failingCore struct {
+	zapcore.LevelEnabler
+}
+
+// With 实现 zapcore.Core
+func (c failingCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+// Sync 实现 zapcore.Core
+func (c failingCore) Sync() error {
+	return nil
+}
+
+// Check 实现 zapcore.Core
+func (c failingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+// Write 实现 zapcore.Core
+func (c failingCore) Write(zapcore.Entry, []zapcore.Field) error {
+	return errors.New("disk full")
+}
+
+// TestCheckInner_ReturnsWriteError 测试内层 core 的写入错误经由外层 CheckedEntry 返回
+func TestCheckInner_ReturnsWriteError(t *testing.T) {
+	core := NewCallerCore(failingCore{zapcore.InfoLevel}, "")
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "x"}
+
+	if ce := core.Check(zapcore.Entry{Level: zapcore.DebugLevel}, nil); ce != nil {
+		t.Fatalf("expected debug entry refused by inner core")
+	}
+
+	ce := core.Check(ent, nil)
+	if ce == nil {
+		t.Fatalf("expected info entry accepted")
+	}
+
+	var out errorOutput
+
+	ce.ErrorOutput = &out
+	ce.Write()
+
+	if err := out.err(); err == nil || !strings.HasSuffix(err.Error(), "disk full") || strings.Count(err.Error(), "write error") != 0 {
+		t.Fatalf("expected inner write error, got %v", err)
+	}
+}
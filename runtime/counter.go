@@ -0,0 +1,207 @@
+//
+// FilePath    : zap-smap\runtime\counter.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 按调用位置与级别统计日志条数
+//
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SiteCounter 按注入字段(调用位置)与级别统计日志条数, 用于发现输出频繁的位置与从未执行的位置。
+// SiteCounter 实现 expvar.Var, 可直接 expvar.Publish; 也实现 http.Handler 输出 JSON 或文本
+type SiteCounter struct {
+	key string
+
+	mu     sync.Mutex
+	counts map[string]map[zapcore.Level]uint64
+}
+
+// CounterSnapshot SiteCounter 的计数快照, 也是 JSON 输出与 zap-smap hotspots 读取的格式:
+// {"sites":{"order/order.go:42":{"info":3,"warn":1}}}
+type CounterSnapshot struct {
+	Sites map[string]map[string]uint64 `json:"sites"`
+}
+
+// NewSiteCounter 创建计数器, 不带注入字段的条目不计数; key 为空时使用 DefaultKey
+func NewSiteCounter(key string) *SiteCounter {
+	if key == "" {
+		key = DefaultKey
+	}
+
+	return &SiteCounter{key: key, counts: make(map[string]map[zapcore.Level]uint64)}
+}
+
+// Wrap 包装 core, 在条目写出时计数, 不改变条目与是否输出
+func (c *SiteCounter) Wrap(core zapcore.Core) zapcore.Core {
+	return &siteCounterCore{Core: core, counter: c}
+}
+
+// Option 返回以 Wrap 包装 logger core 的 zap.Option, 与 WithCaller 同时使用时应放在 WithCaller 之后
+func (c *SiteCounter) Option() zap.Option {
+	return zap.WrapCore(c.Wrap)
+}
+
+// add 为位置 site 的 lvl 级别计数加一
+func (c *SiteCounter) add(site string, lvl zapcore.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.counts[site]
+	if !ok {
+		m = make(map[zapcore.Level]uint64)
+		c.counts[site] = m
+	}
+
+	m[lvl]++
+}
+
+// Snapshot 返回当前计数的快照
+func (c *SiteCounter) Snapshot() CounterSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snap := CounterSnapshot{Sites: make(map[string]map[string]uint64, len(c.counts))}
+
+	for site, m := range c.counts {
+		levels := make(map[string]uint64, len(m))
+		for lvl, n := range m {
+			levels[lvl.String()] = n
+		}
+
+		snap.Sites[site] = levels
+	}
+
+	return snap
+}
+
+// String 实现 expvar.Var, 返回快照的 JSON
+func (c *SiteCounter) String() string {
+	b, err := json.Marshal(c.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+
+	return string(b)
+}
+
+// SiteTotal 一个位置的计数汇总, 用于文本输出
+type SiteTotal struct {
+	Site   string
+	Total  uint64
+	Levels map[string]uint64
+}
+
+// Totals 返回每个位置的计数汇总, 按总数从高到低排序, 总数相同时按位置排序
+func (s CounterSnapshot) Totals() []SiteTotal {
+	totals := make([]SiteTotal, 0, len(s.Sites))
+
+	for site, levels := range s.Sites {
+		t := SiteTotal{Site: site, Levels: levels}
+		for _, n := range levels {
+			t.Total += n
+		}
+
+		totals = append(totals, t)
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Total != totals[j].Total {
+			return totals[i].Total > totals[j].Total
+		}
+
+		return totals[i].Site < totals[j].Site
+	})
+
+	return totals
+}
+
+// String 返回形如 "     42  order/order.go:42  info=40 warn=2" 的一行, 级别按从低到高排列
+func (t SiteTotal) String() string {
+	var parts []string
+
+	for lvl := zapcore.DebugLevel; lvl <= zapcore.FatalLevel; lvl++ {
+		if n, ok := t.Levels[lvl.String()]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", lvl, n))
+		}
+	}
+
+	return fmt.Sprintf("%7d  %s  %s", t.Total, t.Site, strings.Join(parts, " "))
+}
+
+// writeText 按总数从高到低输出每个位置的计数, 每个位置一行
+func (s CounterSnapshot) writeText(w io.Writer) error {
+	for _, t := range s.Totals() {
+		if _, err := fmt.Fprintln(w, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP 输出计数快照, 默认为 JSON, 请求参数 format=text 时按总数从高到低输出文本
+func (c *SiteCounter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = json.NewEncoder(w).Encode(handlerError{Error: "only GET is supported"})
+
+		return
+	}
+
+	snap := c.Snapshot()
+
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = snap.writeText(w)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(snap)
+}
+
+// siteCounterCore 在写出时计数的 core
+type siteCounterCore struct {
+	zapcore.Core
+	counter *SiteCounter
+}
+
+// With 实现 zapcore.Core
+func (c *siteCounterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &siteCounterCore{Core: c.Core.With(fields), counter: c.counter}
+}
+
+// Check 实现 zapcore.Core, 只计数被包装的 core 接收的条目。注入字段只在 Write 时可见, 计数在 Write 中进行
+func (c *siteCounterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checkInner(c.Core, ent, ce, c.write)
+}
+
+// Write 实现 zapcore.Core
+func (c *siteCounterCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.write(ent, fields, c.Core.Write)
+}
+
+// write 计数后写入 next
+func (c *siteCounterCore) write(ent zapcore.Entry, fields []zapcore.Field, next func(zapcore.Entry, []zapcore.Field) error) error {
+	if i, site := findField(fields, c.counter.key); i >= 0 {
+		c.counter.add(site, ent.Level)
+	}
+
+	return next(ent, fields)
+}
@@ -0,0 +1,124 @@
+//
+// FilePath    : zap-smap\runtime\counter_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 按调用位置计数单测
+//
+
+package runtime
+
+import (
+	"encoding/json"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestSiteCounter_Counts 测试按位置与级别计数, 以及 expvar 输出
+func TestSiteCounter_Counts(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	counter := NewSiteCounter("")
+	logger := zap.New(core, counter.Option()).With(zap.String("svc", "order"))
+
+	logger.Info("a", zap.String("fl", "a.go:1"))
+	logger.Info("a", zap.String("fl", "a.go:1"))
+	logger.Warn("a", zap.String("fl", "a.go:1"))
+	logger.Error("b", zap.String("fl", "b.go:2"))
+	logger.Debug("filtered", zap.String("fl", "c.go:3"))
+	logger.Info("no site")
+
+	if logs.Len() != 5 {
+		t.Fatalf("expected entries passed through, got %d", logs.Len())
+	}
+
+	snap := counter.Snapshot()
+	if len(snap.Sites) != 2 || snap.Sites["a.go:1"]["info"] != 2 || snap.Sites["a.go:1"]["warn"] != 1 || snap.Sites["b.go:2"]["error"] != 1 {
+		t.Fatalf("unexpected snapshot: %v", snap.Sites)
+	}
+
+	// expvar 名称只能发布一次, 直接读取未发布的计数器的 expvar.Var 输出
+	var v expvar.Var = counter
+
+	var got CounterSnapshot
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatalf("expvar value: %v", err)
+	}
+
+	if got.Sites["a.go:1"]["info"] != 2 {
+		t.Fatalf("unexpected expvar snapshot: %v", got.Sites)
+	}
+}
+
+// TestSiteCounter_TeeLevels 测试包装 zapcore.NewTee 时每个分支仍按自己的级别过滤条目
+func TestSiteCounter_TeeLevels(t *testing.T) {
+	tee, infoLogs, errorLogs := levelTee()
+	counter := NewSiteCounter("")
+	logger := zap.New(tee, counter.Option())
+
+	logger.Debug("debug", zap.String("fl", "a.go:1"))
+	logger.Info("info", zap.String("fl", "a.go:1"))
+	logger.Error("error", zap.String("fl", "b.go:2"))
+
+	assertTeeLevels(t, infoLogs, errorLogs)
+
+	snap := counter.Snapshot()
+	if len(snap.Sites["a.go:1"]) != 1 || snap.Sites["a.go:1"]["info"] != 1 || snap.Sites["b.go:2"]["error"] != 1 {
+		t.Fatalf("expected each entry counted once, got %v", snap.Sites)
+	}
+}
+
+// TestSiteCounter_ServeHTTP 测试 JSON 与文本输出
+func TestSiteCounter_ServeHTTP(t *testing.T) {
+	counter := NewSiteCounter("")
+	counter.add("a.go:1", zapcore.InfoLevel)
+	counter.add("b.go:2 | app.Run", zapcore.WarnLevel)
+	counter.add("b.go:2 | app.Run", zapcore.ErrorLevel)
+
+	srv := httptest.NewServer(counter)
+	defer srv.Close()
+
+	get := func(url string) (int, string) {
+		t.Helper()
+
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+
+		return resp.StatusCode, string(b)
+	}
+
+	code, body := get(srv.URL)
+	if code != http.StatusOK || !strings.Contains(body, `"a.go:1":{"info":1}`) {
+		t.Fatalf("unexpected JSON: %d %s", code, body)
+	}
+
+	want := "      2  b.go:2 | app.Run  warn=1 error=1\n      1  a.go:1  info=1\n"
+	if code, body := get(srv.URL + "?format=text"); code != http.StatusOK || body != want {
+		t.Fatalf("unexpected text:\n%s\nwant:\n%s", body, want)
+	}
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected method not allowed, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,245 @@
+//
+// FilePath    : zap-smap\runtime\levels.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 按调用位置在运行时调整日志级别
+//
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelRule 位置级别规则: 注入字段的值匹配 Pattern 时, 该位置的最低输出级别为 Level。
+// Pattern 中 * 匹配任意字符(包括 '/'), ? 匹配单个字符, 例如 order/*.go:* 或 *| corp/billing.Charge
+type LevelRule struct {
+	Pattern string        `json:"pattern"`
+	Level   zapcore.Level `json:"level"`
+}
+
+// compiledRule 编译后的位置级别规则
+type compiledRule struct {
+	LevelRule
+	re *regexp.Regexp
+}
+
+// SiteLevels 可在运行时修改的位置级别规则集, 通过 Wrap/Option 包装 core, 通过 ServeHTTP 查看与修改规则
+type SiteLevels struct {
+	base zapcore.LevelEnabler
+	key  string
+
+	mu    sync.RWMutex
+	rules []compiledRule
+}
+
+// NewSiteLevels 创建规则集, 未匹配任何规则或不带注入字段的条目按 base 判断是否输出; key 为空时使用 DefaultKey
+func NewSiteLevels(base zapcore.LevelEnabler, key string) *SiteLevels {
+	if key == "" {
+		key = DefaultKey
+	}
+
+	return &SiteLevels{base: base, key: key}
+}
+
+// compileGlob 将位置模式编译为完整匹配的正则表达式
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// SetRules 校验并替换全部规则, 多条规则匹配同一位置时以最后一条为准
+func (l *SiteLevels) SetRules(rules []LevelRule) error {
+	compiled := make([]compiledRule, 0, len(rules))
+
+	for _, r := range rules {
+		re, err := compileGlob(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", r.Pattern, err)
+		}
+
+		compiled = append(compiled, compiledRule{LevelRule: r, re: re})
+	}
+
+	l.mu.Lock()
+	l.rules = compiled
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Rules 返回当前规则的副本
+func (l *SiteLevels) Rules() []LevelRule {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	rules := make([]LevelRule, 0, len(l.rules))
+	for _, r := range l.rules {
+		rules = append(rules, r.LevelRule)
+	}
+
+	return rules
+}
+
+// Enabled 判断 lvl 是否可能被某个位置输出, 用于 zap 在构造条目前的快速判断
+func (l *SiteLevels) Enabled(lvl zapcore.Level) bool {
+	if l.base.Enabled(lvl) {
+		return true
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, r := range l.rules {
+		if lvl >= r.Level {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allows 判断位置 site 的 lvl 级别条目是否输出, site 为空表示条目不带注入字段
+func (l *SiteLevels) allows(site string, lvl zapcore.Level) bool {
+	if site != "" {
+		l.mu.RLock()
+		defer l.mu.RUnlock()
+
+		for i := len(l.rules) - 1; i >= 0; i-- {
+			if l.rules[i].re.MatchString(site) {
+				return lvl >= l.rules[i].Level
+			}
+		}
+	}
+
+	return l.base.Enabled(lvl)
+}
+
+// Wrap 包装 core, 按规则决定每个条目是否输出。规则放行的条目仍由被包装的 core 按自己的级别过滤,
+// 需要放开 Debug 时被包装的 core 应配置为 Debug 级别
+func (l *SiteLevels) Wrap(core zapcore.Core) zapcore.Core {
+	return &siteLevelCore{Core: core, levels: l}
+}
+
+// Option 返回以 Wrap 包装 logger core 的 zap.Option, 与 WithCaller 同时使用时应放在 WithCaller 之后
+func (l *SiteLevels) Option() zap.Option {
+	return zap.WrapCore(l.Wrap)
+}
+
+// siteLevelCore 按位置级别规则过滤条目的 core
+type siteLevelCore struct {
+	zapcore.Core
+	levels *SiteLevels
+}
+
+// Enabled 实现 zapcore.Core
+func (c *siteLevelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.Enabled(lvl)
+}
+
+// With 实现 zapcore.Core
+func (c *siteLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &siteLevelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+// Check 实现 zapcore.Core。注入字段只在 Write 时可见, 位置规则在 Write 中判断
+func (c *siteLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	return checkInner(c.Core, ent, ce, c.write)
+}
+
+// Write 实现 zapcore.Core
+func (c *siteLevelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.write(ent, fields, c.Core.Write)
+}
+
+// write 位置规则放行时写入 next
+func (c *siteLevelCore) write(ent zapcore.Entry, fields []zapcore.Field, next func(zapcore.Entry, []zapcore.Field) error) error {
+	_, site := findField(fields, c.levels.key)
+	if !c.levels.allows(site, ent.Level) {
+		return nil
+	}
+
+	return next(ent, fields)
+}
+
+// siteLevelsPayload ServeHTTP 的请求与响应体
+type siteLevelsPayload struct {
+	Rules []LevelRule `json:"rules"`
+}
+
+// handlerError ServeHTTP 的错误响应体, SiteLevels 与 SiteCounter 共用
+type handlerError struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP 查看与修改规则, 用法与 zap.AtomicLevel 的 handler 相同:
+//   - GET 返回 {"rules":[{"pattern":"order/*.go:*","level":"debug"}]}
+//   - PUT 以相同格式的请求体替换全部规则
+func (l *SiteLevels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req siteLevelsPayload
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(handlerError{Error: fmt.Sprintf("decode request: %v", err)})
+
+			return
+		}
+
+		if err := l.SetRules(req.Rules); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(handlerError{Error: err.Error()})
+
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = enc.Encode(handlerError{Error: "only GET and PUT are supported"})
+
+		return
+	}
+
+	_ = enc.Encode(siteLevelsPayload{Rules: l.Rules()})
+}
@@ -0,0 +1,137 @@
+//
+// FilePath    : zap-smap\runtime\levels_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 按调用位置调整级别单测
+//
+
+package runtime
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestSiteLevels_Rules 测试按位置模式放开或收紧级别, 后面的规则优先
+func TestSiteLevels_Rules(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	levels := NewSiteLevels(zapcore.InfoLevel, "")
+
+	err := levels.SetRules([]LevelRule{
+		{Pattern: "order/*.go:*", Level: zapcore.DebugLevel},
+		{Pattern: "*| corp/billing.Charge", Level: zapcore.DebugLevel},
+		{Pattern: "order/noisy.go:*", Level: zapcore.ErrorLevel},
+	})
+	if err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+
+	logger := zap.New(core, levels.Option())
+
+	logger.Debug("order debug", zap.String("fl", "order/sub/a.go:3"))
+	logger.Debug("billing debug", zap.String("fl", "billing/b.go:9 | corp/billing.Charge"))
+	logger.Debug("other debug", zap.String("fl", "billing/b.go:12 | corp/billing.Refund"))
+	logger.Debug("no site debug")
+	logger.Info("noisy info", zap.String("fl", "order/noisy.go:5"))
+	logger.Error("noisy error", zap.String("fl", "order/noisy.go:6"))
+	logger.Info("no site info")
+
+	want := []string{"order debug", "billing debug", "noisy error", "no site info"}
+	got := messages(logs)
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if err := levels.SetRules([]LevelRule{{Pattern: ""}}); err == nil {
+		t.Fatalf("expected error for empty pattern")
+	}
+
+	if len(levels.Rules()) != 3 {
+		t.Fatalf("expected rules unchanged after invalid update, got %v", levels.Rules())
+	}
+}
+
+// TestSiteLevels_TeeLevels 测试规则放行的条目仍由 zapcore.NewTee 的每个分支按自己的级别过滤
+func TestSiteLevels_TeeLevels(t *testing.T) {
+	tee, infoLogs, errorLogs := levelTee()
+	levels := NewSiteLevels(zapcore.InfoLevel, "")
+
+	if err := levels.SetRules([]LevelRule{{Pattern: "a.go:*", Level: zapcore.DebugLevel}}); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+
+	logger := zap.New(tee, levels.Option())
+
+	logger.Debug("debug", zap.String("fl", "a.go:1"))
+	logger.Info("info", zap.String("fl", "a.go:2"))
+	logger.Error("error", zap.String("fl", "b.go:3"))
+
+	assertTeeLevels(t, infoLogs, errorLogs)
+
+	if infoLogs.FilterMessage("debug").Len() != 0 {
+		t.Fatalf("expected debug entry dropped by info branch, got %v", messages(infoLogs))
+	}
+}
+
+// TestSiteLevels_ServeHTTP 测试通过 HTTP 查看与修改规则
+func TestSiteLevels_ServeHTTP(t *testing.T) {
+	levels := NewSiteLevels(zapcore.InfoLevel, "")
+	srv := httptest.NewServer(levels)
+	defer srv.Close()
+
+	do := func(method, body string) (int, string) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, srv.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+
+		return resp.StatusCode, strings.TrimSpace(string(b))
+	}
+
+	if code, body := do(http.MethodGet, ""); code != http.StatusOK || body != `{"rules":[]}` {
+		t.Fatalf("unexpected GET: %d %s", code, body)
+	}
+
+	code, body := do(http.MethodPut, `{"rules":[{"pattern":"order/*.go:*","level":"debug"}]}`)
+	if code != http.StatusOK || body != `{"rules":[{"pattern":"order/*.go:*","level":"debug"}]}` {
+		t.Fatalf("unexpected PUT: %d %s", code, body)
+	}
+
+	if !levels.allows("order/a.go:1", zapcore.DebugLevel) {
+		t.Fatalf("expected rule applied after PUT")
+	}
+
+	if code, body := do(http.MethodPut, `{"rules":[{"pattern":"x","level":"loud"}]}`); code != http.StatusBadRequest || !strings.Contains(body, "error") {
+		t.Fatalf("expected bad request for invalid level, got %d %s", code, body)
+	}
+
+	if code, _ := do(http.MethodPost, ""); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected method not allowed, got %d", code)
+	}
+
+	if got := levels.Rules(); len(got) != 1 || got[0].Pattern != "order/*.go:*" {
+		t.Fatalf("expected rules unchanged after failed PUT, got %v", got)
+	}
+}
@@ -0,0 +1,323 @@
+//
+// FilePath    : zap-smap\runtime\sampler.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 按调用位置采样与限流
+//
+
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SiteSampler 按注入字段(调用位置)采样的 zapcore.Core。
+// 与 zap 按消息采样不同, 不同位置的同名消息各自计数, 一个频繁输出的位置不会挤占其他位置的配额
+type SiteSampler struct {
+	zapcore.Core
+
+	key        string
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	clock      zapcore.Clock
+	state      *samplerState
+}
+
+// SamplerOption SiteSampler 的可选配置
+type SamplerOption func(*SiteSampler)
+
+// SamplerKey 设置注入字段的键, 默认 DefaultKey
+func SamplerKey(key string) SamplerOption {
+	return func(s *SiteSampler) {
+		s.key = key
+	}
+}
+
+// SamplerClock 设置时钟, 默认 zapcore.DefaultClock, 主要用于测试
+func SamplerClock(clock zapcore.Clock) SamplerOption {
+	return func(s *SiteSampler) {
+		s.clock = clock
+	}
+}
+
+// samplerState 采样计数与后台汇总协程, 由 With 派生的 core 共享
+type samplerState struct {
+	mu    sync.Mutex
+	sites map[string]*siteCounter
+	stop  chan struct{} // Start 启动后非 nil, 关闭时后台协程退出
+	done  chan struct{} // 后台协程退出后关闭
+
+	evictAt int64 // 写出路径下次移除过期计数的时间(纳秒)
+}
+
+// siteCounter 单个位置在当前周期内的计数
+type siteCounter struct {
+	site       string        // 位置描述, 用于汇总消息
+	resetAt    int64         // 当前周期的结束时间(纳秒)
+	n          uint64        // 当前周期内的条目数
+	suppressed uint64        // 当前周期内被丢弃的条目数
+	level      zapcore.Level // 被丢弃条目的最高级别, 汇总条目使用该级别
+}
+
+// suppression 一条待输出的丢弃汇总
+type suppression struct {
+	site  string
+	n     uint64
+	level zapcore.Level
+}
+
+// NewSiteSampler 包装 core: 每个调用位置在每个 tick 周期内输出前 first 条, 之后每 thereafter 条输出一条(thereafter 为 0 时全部丢弃)。
+// 位置以注入字段的值为键, 不带注入字段的条目以级别与消息为键。
+// 周期结束后的下一条日志之前输出 "suppressed N entries at site X" 汇总并移除周期已结束的位置, Flush 与 Sync 立即输出所有待汇总的位置,
+// Start 之后每个周期自动输出汇总。被包装 core 的级别仍然生效, 不接收的条目不计数
+func NewSiteSampler(core zapcore.Core, tick time.Duration, first, thereafter int, opts ...SamplerOption) *SiteSampler {
+	s := &SiteSampler{
+		Core:       core,
+		key:        DefaultKey,
+		tick:       tick,
+		first:      uint64(max(first, 0)),
+		thereafter: uint64(max(thereafter, 0)),
+		clock:      zapcore.DefaultClock,
+		state:      &samplerState{sites: make(map[string]*siteCounter)},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithSiteSampler 返回以 NewSiteSampler 包装 logger core 的 zap.Option。
+// 与 WithCaller 同时使用时应放在 WithCaller 之后, 以便在注入字段被移除之前完成采样。
+// 需要 Start 定期输出汇总时改用 NewSiteSampler 并以 zap.WrapCore 包装
+func WithSiteSampler(tick time.Duration, first, thereafter int, opts ...SamplerOption) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewSiteSampler(core, tick, first, thereafter, opts...)
+	})
+}
+
+// With 实现 zapcore.Core, 派生的 core 共享采样计数
+func (s *SiteSampler) With(fields []zapcore.Field) zapcore.Core {
+	c := *s
+	c.Core = s.Core.With(fields)
+
+	return &c
+}
+
+// Check 实现 zapcore.Core, 由被包装的 core 决定是否接收条目。注入字段只在 Write 时可见, 采样在 Write 中进行
+func (s *SiteSampler) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checkInner(s.Core, ent, ce, s.write)
+}
+
+// Write 实现 zapcore.Core
+func (s *SiteSampler) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return s.write(ent, fields, s.Core.Write)
+}
+
+// write 采样后输出待汇总的丢弃数, 本条未被丢弃时写入 next
+func (s *SiteSampler) write(ent zapcore.Entry, fields []zapcore.Field, next func(zapcore.Entry, []zapcore.Field) error) error {
+	key, site := ent.Level.String()+"\x00"+ent.Message, fmt.Sprintf("message %q", ent.Message)
+	if i, v := findField(fields, s.key); i >= 0 {
+		key, site = v, v
+	}
+
+	allow, pending := s.sample(key, site, ent.Level)
+
+	if err := s.writeSuppressions(pending); err != nil {
+		return err
+	}
+
+	if !allow {
+		return nil
+	}
+
+	return next(ent, fields)
+}
+
+// sample 更新 key 的计数, 返回本条是否输出以及待输出的丢弃汇总。
+// 每个 tick 周期还会移除一次所有周期已结束的计数, 未调用 Start 时计数同样只保留最近活跃的位置
+func (s *SiteSampler) sample(key, site string, level zapcore.Level) (bool, []suppression) {
+	now := s.clock.Now().UnixNano()
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	var pending []suppression
+
+	if now >= s.state.evictAt {
+		pending = s.state.evictExpired(now)
+		s.state.evictAt = now + int64(s.tick)
+	}
+
+	c, ok := s.state.sites[key]
+	if !ok {
+		c = &siteCounter{site: site, resetAt: now + int64(s.tick)}
+		s.state.sites[key] = c
+	}
+
+	if now >= c.resetAt {
+		if c.suppressed > 0 {
+			pending = append(pending, suppression{site: c.site, n: c.suppressed, level: c.level})
+		}
+
+		c.n, c.suppressed, c.resetAt = 0, 0, now+int64(s.tick)
+	}
+
+	c.n++
+
+	if c.n <= s.first || (s.thereafter > 0 && (c.n-s.first)%s.thereafter == 0) {
+		return true, pending
+	}
+
+	if c.suppressed == 0 || level > c.level {
+		c.level = level
+	}
+
+	c.suppressed++
+
+	return false, pending
+}
+
+// Flush 立即输出所有位置待输出的丢弃汇总
+func (s *SiteSampler) Flush() error {
+	s.state.mu.Lock()
+
+	var pending []suppression
+
+	for _, c := range s.state.sites {
+		if c.suppressed > 0 {
+			pending = append(pending, suppression{site: c.site, n: c.suppressed, level: c.level})
+			c.suppressed = 0
+		}
+	}
+
+	s.state.mu.Unlock()
+
+	return s.writeSuppressions(pending)
+}
+
+// Start 启动后台协程, 每个 tick 周期输出周期已结束的位置的丢弃汇总并移除这些位置的计数,
+// 汇总因此不依赖再次输出日志。重复调用无效, 不再使用时调用 Stop
+func (s *SiteSampler) Start() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	if s.state.stop != nil {
+		return
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.state.stop, s.state.done = stop, done
+
+	ticker := s.clock.NewTicker(s.tick)
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				_ = s.sweep()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止 Start 启动的后台协程并输出所有待汇总的丢弃数
+func (s *SiteSampler) Stop() error {
+	s.state.mu.Lock()
+	stop, done := s.state.stop, s.state.done
+	s.state.stop, s.state.done = nil, nil
+	s.state.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	return s.Flush()
+}
+
+// sweep 输出周期已结束的位置的丢弃汇总并移除其计数
+func (s *SiteSampler) sweep() error {
+	now := s.clock.Now().UnixNano()
+
+	s.state.mu.Lock()
+	pending := s.state.evictExpired(now)
+	s.state.mu.Unlock()
+
+	return s.writeSuppressions(pending)
+}
+
+// evictExpired 移除周期已结束的计数并返回其中待输出的丢弃汇总, 调用方需持有 mu。
+// 周期已结束的计数在该位置的下一条日志时会被重置, 与新建的计数等价, 移除不影响采样结果
+func (st *samplerState) evictExpired(now int64) []suppression {
+	var pending []suppression
+
+	for key, c := range st.sites {
+		if now < c.resetAt {
+			continue
+		}
+
+		if c.suppressed > 0 {
+			pending = append(pending, suppression{site: c.site, n: c.suppressed, level: c.level})
+		}
+
+		delete(st.sites, key)
+	}
+
+	return pending
+}
+
+// writeSuppressions 按位置排序后依次输出丢弃汇总
+func (s *SiteSampler) writeSuppressions(pending []suppression) error {
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].site < pending[j].site
+	})
+
+	for _, p := range pending {
+		if err := s.writeSuppression(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sync 实现 zapcore.Core, 先输出待汇总的丢弃数
+func (s *SiteSampler) Sync() error {
+	if err := s.Flush(); err != nil {
+		return err
+	}
+
+	return s.Core.Sync()
+}
+
+// writeSuppression 输出一条丢弃汇总, 级别为被丢弃条目的最高级别
+func (s *SiteSampler) writeSuppression(p suppression) error {
+	ent := zapcore.Entry{
+		Level:   p.level,
+		Time:    s.clock.Now(),
+		Message: fmt.Sprintf("suppressed %d entries at site %s", p.n, p.site),
+	}
+
+	// 汇总同样由被包装的 core 按级别过滤
+	ce := s.Core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	return writeChecked(ce, ent, []zapcore.Field{zap.Uint64("suppressed", p.n)})
+}
@@ -0,0 +1,234 @@
+//
+// FilePath    : zap-smap\runtime\sampler_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 按调用位置采样单测
+//
+
+package runtime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+// Now 实现 zapcore.Clock
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// NewTicker 实现 zapcore.Clock
+func (c *fakeClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+// messages 返回观察到的条目消息列表
+func messages(logs *observer.ObservedLogs) []string {
+	var ms []string
+	for _, e := range logs.All() {
+		ms = append(ms, e.Message)
+	}
+
+	return ms
+}
+
+// TestSiteSampler_PerSiteBudget 测试不同位置的同名消息各自计数, 以及 first/thereafter 配额
+func TestSiteSampler_PerSiteBudget(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	logger := zap.New(core, WithSiteSampler(time.Second, 2, 3, SamplerClock(clock)))
+
+	for range 8 {
+		logger.Warn("db timeout", zap.String("fl", "a.go:1"))
+	}
+
+	logger.Warn("db timeout", zap.String("fl", "b.go:2"))
+
+	// a.go:1 输出第 1、2、5、8 条, b.go:2 不受 a.go:1 影响
+	if got := len(logs.FilterField(zap.String("fl", "a.go:1")).All()); got != 4 {
+		t.Fatalf("expected 4 entries for a.go:1, got %d", got)
+	}
+
+	if got := len(logs.FilterField(zap.String("fl", "b.go:2")).All()); got != 1 {
+		t.Fatalf("expected 1 entry for b.go:2, got %d", got)
+	}
+}
+
+// TestSiteSampler_SuppressionSummary 测试周期结束后的下一条日志输出所有已结束周期的丢弃汇总, 以及 Sync 立即输出汇总
+func TestSiteSampler_SuppressionSummary(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := NewSiteSampler(core, time.Second, 1, 0, SamplerClock(clock))
+	logger := zap.New(s).With(zap.String("svc", "order"))
+
+	logger.Info("hit", zap.String("fl", "a.go:1"))
+	logger.Info("hit", zap.String("fl", "a.go:1"))
+	logger.Error("hit", zap.String("fl", "a.go:1"))
+	logger.Info("no site")
+	logger.Info("no site")
+
+	clock.now = clock.now.Add(time.Second)
+	logger.Info("hit", zap.String("fl", "a.go:1"))
+
+	// 汇总按位置排序, 不带注入字段的条目以消息为键
+	want := []string{"hit", "no site", "suppressed 2 entries at site a.go:1", `suppressed 1 entries at site message "no site"`, "hit"}
+	if got := messages(logs); len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		}
+	}
+
+	summary := logs.All()[2]
+	if summary.Level != zapcore.ErrorLevel || summary.ContextMap()["suppressed"] != uint64(2) {
+		t.Fatalf("unexpected summary entry: %v %v", summary.Level, summary.ContextMap())
+	}
+
+	// 新周期内的丢弃汇总在 Sync 时输出
+	logger.Info("no site")
+	logger.Info("no site")
+
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	last := logs.All()[logs.Len()-1]
+	if last.Message != `suppressed 1 entries at site message "no site"` {
+		t.Fatalf("expected summary on Sync, got %q", last.Message)
+	}
+
+	if err := s.Flush(); err != nil || logs.Len() != 7 {
+		t.Fatalf("expected no pending summary after Sync, got %d entries, err %v", logs.Len(), err)
+	}
+}
+
+// TestSiteSampler_SweepEvictsIdleSites 测试周期结束的位置输出汇总并移除计数, 仍在周期内的位置保留
+func TestSiteSampler_SweepEvictsIdleSites(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := NewSiteSampler(core, time.Second, 1, 0, SamplerClock(clock))
+	logger := zap.New(s)
+
+	logger.Warn("hit", zap.String("fl", "a.go:1"))
+	logger.Warn("hit", zap.String("fl", "a.go:1"))
+	logger.Info("once", zap.String("fl", "b.go:2"))
+
+	clock.now = clock.now.Add(time.Second / 2)
+	logger.Info("fresh", zap.String("fl", "c.go:3"))
+
+	clock.now = clock.now.Add(time.Second / 2)
+
+	if err := s.sweep(); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	want := []string{"hit", "once", "fresh", "suppressed 1 entries at site a.go:1"}
+	if got := messages(logs); len(got) != len(want) || got[3] != want[3] {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if _, ok := s.state.sites["c.go:3"]; !ok || len(s.state.sites) != 1 {
+		t.Fatalf("expected only c.go:3 kept, got %d sites", len(s.state.sites))
+	}
+
+	// 被移除的位置重新计数, 与周期重置后的行为一致
+	logger.Warn("hit", zap.String("fl", "a.go:1"))
+
+	if got := len(logs.FilterMessage("hit").All()); got != 2 {
+		t.Fatalf("expected evicted site to start a new period, got %d entries", got)
+	}
+}
+
+// TestSiteSampler_EvictsWithoutStart 测试未调用 Start 时写出路径也会移除周期已结束的位置
+func TestSiteSampler_EvictsWithoutStart(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := NewSiteSampler(core, time.Second, 1, 0, SamplerClock(clock))
+	logger := zap.New(s)
+
+	for i := range 100 {
+		logger.Info("hit", zap.String("fl", fmt.Sprintf("a.go:%d", i+1)))
+	}
+
+	logger.Info("hit", zap.String("fl", "a.go:1"))
+
+	clock.now = clock.now.Add(time.Second)
+	logger.Info("fresh", zap.String("fl", "b.go:1"))
+
+	if _, ok := s.state.sites["b.go:1"]; !ok || len(s.state.sites) != 1 {
+		t.Fatalf("expected only b.go:1 kept, got %d sites", len(s.state.sites))
+	}
+
+	if logs.FilterMessage("suppressed 1 entries at site a.go:1").Len() != 1 {
+		t.Fatalf("expected summary for evicted site, got %v", messages(logs))
+	}
+}
+
+// TestSiteSampler_TeeLevels 测试包装 zapcore.NewTee 时每个分支仍按自己的级别过滤条目和丢弃汇总
+func TestSiteSampler_TeeLevels(t *testing.T) {
+	tee, infoLogs, errorLogs := levelTee()
+	s := NewSiteSampler(tee, time.Second, 1, 0)
+	logger := zap.New(s)
+
+	logger.Info("info", zap.String("fl", "a.go:1"))
+	logger.Info("info", zap.String("fl", "a.go:1"))
+	logger.Error("error", zap.String("fl", "b.go:2"))
+
+	if err := s.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	assertTeeLevels(t, infoLogs, errorLogs)
+
+	if infoLogs.FilterMessage("suppressed 1 entries at site a.go:1").Len() != 1 || errorLogs.Len() != 1 {
+		t.Fatalf("expected info summary only on info branch, got %v and %v", messages(infoLogs), messages(errorLogs))
+	}
+}
+
+// TestSiteSampler_StartFlushesPeriodically 测试 Start 之后位置不再输出日志时汇总也会按周期输出, Stop 之后协程退出
+func TestSiteSampler_StartFlushesPeriodically(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	s := NewSiteSampler(core, 20*time.Millisecond, 1, 0)
+	logger := zap.New(s)
+
+	s.Start()
+	s.Start()
+
+	logger.Info("hit", zap.String("fl", "a.go:1"))
+	logger.Info("hit", zap.String("fl", "a.go:1"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.FilterMessage("suppressed 1 entries at site a.go:1").Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected periodic summary, got %v", messages(logs))
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	if s.state.stop != nil {
+		t.Fatalf("expected background goroutine stopped")
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("second stop: %v", err)
+	}
+}
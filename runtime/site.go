@@ -0,0 +1,78 @@
+//
+// FilePath    : zap-smap\runtime\site.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 注入字段的解析
+//
+
+// Package runtime 是 zap-smap 的运行时配套包, 提供基于注入字段(默认 fl)工作的 zapcore.Core。
+//
+// 该包名与标准库 runtime 相同, 建议导入时使用别名:
+//
+//	import smaprt "github.com/jiaopengzi/zap-smap/runtime"
+package runtime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultKey zap-smap 默认注入的字段键
+const DefaultKey = "fl"
+
+// Site 注入字段描述的调用位置
+type Site struct {
+	File string `json:"file"`           // 相对仓库根的文件路径, 使用 '/' 分隔
+	Line int    `json:"line"`           // 行号
+	Func string `json:"func,omitempty"` // 完整函数名, 注入时未使用 -with-func 则为空
+}
+
+// String 返回 file:line | func 形式的位置描述, 与 zap-smap 注入的值一致
+func (s Site) String() string {
+	v := fmt.Sprintf("%s:%d", s.File, s.Line)
+	if s.Func != "" {
+		v += " | " + s.Func
+	}
+
+	return v
+}
+
+// ParseSite 解析 "file:line" 或 "file:line | func" 形式的注入值
+func ParseSite(v string) (Site, bool) {
+	loc, fn, _ := strings.Cut(v, " | ")
+
+	i := strings.LastIndex(loc, ":")
+	if i <= 0 {
+		return Site{}, false
+	}
+
+	line, err := strconv.Atoi(loc[i+1:])
+	if err != nil || line <= 0 {
+		return Site{}, false
+	}
+
+	return Site{File: loc[:i], Line: line, Func: fn}, true
+}
+
+// findField 在 fields 中查找键为 key 的字符串字段, 返回其索引与值, 未找到返回 -1
+func findField(fields []zapcore.Field, key string) (int, string) {
+	for i, f := range fields {
+		if f.Key == key && f.Type == zapcore.StringType {
+			return i, f.String
+		}
+	}
+
+	return -1, ""
+}
+
+// withoutField 返回移除第 i 个字段后的新切片, 不修改原切片
+func withoutField(fields []zapcore.Field, i int) []zapcore.Field {
+	out := make([]zapcore.Field, 0, len(fields)-1)
+	out = append(out, fields[:i]...)
+
+	return append(out, fields[i+1:]...)
+}
@@ -0,0 +1,234 @@
+//
+// FilePath    : zap-smap\slog_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : log/slog backend 单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// slogSample 覆盖 slog 包级函数、*slog.Logger 方法、Context 方法、Log/LogAttrs 以及 ellipsis 调用
+const slogSample = `package sample
+
+import (
+	"context"
+	"log/slog"
+)
+
+func Foo(ctx context.Context, args []any, attrs []slog.Attr) {
+	logger := slog.Default().With("svc", "foo")
+
+	slog.Info("pkg", "a", 1)
+	logger.WarnContext(ctx, "ctx")
+	logger.Log(ctx, slog.LevelError, "log", slog.Int("n", 1))
+	slog.LogAttrs(ctx, slog.LevelInfo, "attrs", attrs...)
+	slog.Debug("args", args...)
+}
+`
+
+// runSlogSample 写入 content 并以 -write 运行工具, 返回处理后的文件内容
+func runSlogSample(t *testing.T, content string) string {
+	t.Helper()
+
+	td := t.TempDir()
+	writeFile(t, td, "s.go", content)
+
+	*pathFlag = td
+	*writeFlg = true
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	b, err := os.ReadFile(filepath.Join(td, "s.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	return string(b)
+}
+
+// TestMain_Slog_InjectsAllCallForms 测试 slog 各种调用形式在 msg 之后注入 slog.String
+func TestMain_Slog_InjectsAllCallForms(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	*fieldFlg = "fl"
+
+	s := runSlogSample(t, slogSample)
+
+	wants := []string{
+		`slog.Info("pkg", slog.String("fl", "s.go:11"), "a", 1)`,
+		`logger.WarnContext(ctx, "ctx", slog.String("fl", "s.go:12"))`,
+		`logger.Log(ctx, slog.LevelError, "log", slog.String("fl", "s.go:13"), slog.Int("n", 1))`,
+		`slog.LogAttrs(ctx, slog.LevelInfo, "attrs", append([]slog.Attr{slog.String("fl", "s.go:14")}, attrs...)...)`,
+		`slog.Debug("args", append([]any{slog.String("fl", "s.go:15")}, args...)...)`,
+	}
+	for _, w := range wants {
+		if !strings.Contains(s, w) {
+			t.Fatalf("expected %s, got:\n%s", w, s)
+		}
+	}
+
+	// 派生 logger 的 With 参数不是日志调用
+	if !strings.Contains(s, `slog.Default().With("svc", "foo")`) {
+		t.Fatalf("expected With call untouched, got:\n%s", s)
+	}
+}
+
+// TestMain_Slog_KeyValuePairForm 测试键值对形式的字段被识别: 更新时保留键值对形式, -del 时移除键与值
+func TestMain_Slog_KeyValuePairForm(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	*fieldFlg = "fl"
+
+	s := runSlogSample(t, `package sample
+
+import "log/slog"
+
+func Foo() {
+	slog.Info("kv", "a", 1, "fl", "old.go:1")
+}
+`)
+
+	if !strings.Contains(s, `slog.Info("kv", "a", 1, "fl", "s.go:6")`) {
+		t.Fatalf("expected key-value pair updated in place, got:\n%s", s)
+	}
+
+	resetGlobals()
+	resetNewFlags()
+
+	*delFlg = "fl"
+
+	s = runSlogSample(t, s)
+
+	if !strings.Contains(s, `slog.Info("kv", "a", 1)`) {
+		t.Fatalf("expected key-value pair removed, got:\n%s", s)
+	}
+}
+
+// TestMain_Slog_PositionAndSort 测试 -position 以字段(键值对计为一个字段)计数, -sort 将键值对整体排序
+func TestMain_Slog_PositionAndSort(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	*fieldFlg = "fl"
+	*positionFlg = 1
+
+	s := runSlogSample(t, `package sample
+
+import "log/slog"
+
+func Foo() {
+	slog.Info("pos", "a", 1, slog.Int("b", 2))
+}
+`)
+
+	if !strings.Contains(s, `slog.Info("pos", "a", 1, slog.String("fl", "s.go:6"), slog.Int("b", 2))`) {
+		t.Fatalf("expected field inserted after the first key-value pair, got:\n%s", s)
+	}
+
+	resetGlobals()
+	resetNewFlags()
+
+	*fieldFlg = "fl"
+	*sortFlg = true
+
+	s = runSlogSample(t, `package sample
+
+import "log/slog"
+
+func Foo() {
+	slog.Info("sort", "z", 1, slog.Int("b", 2))
+}
+`)
+
+	if !strings.Contains(s, `slog.Info("sort", slog.Int("b", 2), slog.String("fl", "s.go:6"), "z", 1)`) {
+		t.Fatalf("expected fields sorted by key, got:\n%s", s)
+	}
+}
+
+// TestMain_Slog_Verify 测试 -verify 识别 slog 调用, 键值对形式的正确字段视为通过
+func TestMain_Slog_Verify(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "v.go", `package sample
+
+import "log/slog"
+
+func Foo() {
+	slog.Info("ok", "fl", "v.go:6")
+	slog.Warn("stale", slog.String("fl", "v.go:1"))
+	slog.Error("missing")
+}
+`)
+
+	*pathFlag = td
+	*verifyFlg = true
+	*fieldFlg = "fl"
+	os.Args = []string{"cmd"}
+
+	out := captureOutput(func() { main() })
+
+	if !strings.Contains(out, "total calls: 3") {
+		t.Fatalf("expected 3 slog calls counted, got:\n%s", out)
+	}
+	if !strings.Contains(out, "missing: 1") || !strings.Contains(out, "mismatch: 1") {
+		t.Fatalf("expected one missing and one mismatch, got:\n%s", out)
+	}
+	if !strings.Contains(out, "slog.Error missing field 'fl'") {
+		t.Fatalf("expected slog method name in issue, got:\n%s", out)
+	}
+}
+
+// TestMain_Slog_BackendChosenByImports 测试 backend 由导入决定: 未导入 log/slog 的文件中同名调用不被处理
+func TestMain_Slog_BackendChosenByImports(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	*fieldFlg = "fl"
+
+	s := runSlogSample(t, `package sample
+
+import (
+	"log/slog"
+
+	"go.uber.org/zap"
+)
+
+func Foo(l *slog.Logger) {
+	zap.L().Info("zap")
+	slog.Info("slog")
+}
+`)
+
+	if !strings.Contains(s, `zap.L().Info("zap", zap.String("fl", "s.go:10"))`) {
+		t.Fatalf("expected zap call injected with zap.String, got:\n%s", s)
+	}
+	if !strings.Contains(s, `slog.Info("slog", slog.String("fl", "s.go:11"))`) {
+		t.Fatalf("expected slog call injected with slog.String, got:\n%s", s)
+	}
+
+	s = runSlogSample(t, `package sample
+
+import "example.com/slog"
+
+func Foo() {
+	slog.Info("other")
+}
+`)
+
+	if !strings.Contains(s, `slog.Info("other")`) {
+		t.Fatalf("expected call of a non log/slog package untouched, got:\n%s", s)
+	}
+}
@@ -0,0 +1,298 @@
+//
+// FilePath    : zap-smap\smap.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -token 模式的位置令牌与 smap.json 映射文件
+//
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jiaopengzi/go-utils"
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+const (
+	// smapVersion smap.json 的格式版本
+	smapVersion = 1
+
+	// tokenKeyEnv 未指定 -token-key 时读取密钥的环境变量
+	tokenKeyEnv = "ZAP_SMAP_TOKEN_KEY"
+
+	// tokenLen 令牌长度(十六进制字符数)
+	tokenLen = 16
+)
+
+var (
+	// tokenKey -token 模式使用的 HMAC 密钥
+	tokenKey []byte
+
+	// siteRegistry 本次运行收集的 令牌 → 源码位置, 仅在 -token 模式下非 nil
+	siteRegistry map[string]smaprt.Site
+)
+
+// sourceMap smap.json 的内容
+type sourceMap struct {
+	Version int                    `json:"version"`
+	Entries map[string]smaprt.Site `json:"entries"`
+}
+
+// initTokenMode 解析 -token 与 -token-key 参数, 初始化密钥与位置登记表
+func initTokenMode() error {
+	tokenKey = nil
+	siteRegistry = nil
+
+	if !*tokenFlg {
+		return nil
+	}
+
+	key := *tokenKeyFlg
+	if key == "" {
+		key = os.Getenv(tokenKeyEnv)
+	}
+
+	if key == "" {
+		return fmt.Errorf("-token requires -token-key or the %s environment variable", tokenKeyEnv)
+	}
+
+	tokenKey = []byte(key)
+	siteRegistry = make(map[string]smaprt.Site)
+
+	return nil
+}
+
+// siteToken 计算位置的令牌: 以 tokenKey 为密钥对 "file:line | func" 做 HMAC-SHA256, 取前 tokenLen 个十六进制字符
+func siteToken(file string, line int, funcFull string) string {
+	mac := hmac.New(sha256.New, tokenKey)
+	mac.Write([]byte(smaprt.Site{File: file, Line: line, Func: funcFull}.String()))
+
+	return hex.EncodeToString(mac.Sum(nil))[:tokenLen]
+}
+
+// smapEnabled 判断本次运行是否需要维护 smap.json, -del 删除字段时不维护
+func smapEnabled() bool {
+	return siteRegistry != nil && *delFlg == ""
+}
+
+// smapPath 返回映射文件路径, 相对路径基于 baseDir
+func smapPath(baseDir string) string {
+	if filepath.IsAbs(*smapFlg) {
+		return *smapFlg
+	}
+
+	return filepath.Join(baseDir, *smapFlg)
+}
+
+// smapScope 返回本次运行覆盖的相对路径范围: 目录模式为目录相对 baseDir 的路径, 单文件模式为文件本身
+func smapScope(target, baseDir string) string {
+	return siteRel(target, baseDir)
+}
+
+// inSmapScope 判断相对路径 file 是否位于任一 scope 范围内, 以 /* 结尾的范围只包含目录中的文件, 不含子目录
+func inSmapScope(file string, scopes []string) bool {
+	for _, scope := range scopes {
+		if dir, ok := strings.CutSuffix(scope, "/*"); ok {
+			if pathpkg.Dir(file) == dir {
+				return true
+			}
+
+			continue
+		}
+
+		if scope == "." || scope == "" || file == scope || strings.HasPrefix(file, strings.TrimSuffix(scope, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordSites 将文件最终内容中每个注入目标的令牌与位置登记到 siteRegistry。
+// modified 为 true 时使用生成内容 out, 否则读取磁盘上的文件
+func recordSites(path string, modified bool, out string, modulePath, baseDir string) {
+	if !smapEnabled() {
+		return
+	}
+
+	src, err := finalSource(path, modified, out)
+	if err != nil {
+		return
+	}
+
+	fSet := token.NewFileSet()
+
+	file, err := parser.ParseFile(fSet, path, src, parser.ParseComments)
+	if err != nil {
+		return
+	}
+
+	sc := newFileScope(file, fSet)
+	if len(sc.backends) == 0 {
+		return
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		lc, ok := matchLogCall(ce, sc)
+		if !ok {
+			return true
+		}
+
+		isTarget, pos, rel, funcName, pkgName, expected, _ := analyzeCallExpr(lc, fSet, sc, modulePath, baseDir)
+		if isTarget {
+			siteRegistry[expected] = smaprt.Site{File: rel, Line: pos.Line, Func: qualifiedFuncName(fileImportPath(pos.Filename, rel, modulePath), funcName, pkgName)}
+		}
+
+		return true
+	})
+}
+
+// finalSource 返回文件的最终内容: modified 为 true 时为生成内容 out, 否则为磁盘上的文件
+func finalSource(path string, modified bool, out string) ([]byte, error) {
+	if modified {
+		return []byte(out), nil
+	}
+
+	return utils.ReadFile(path)
+}
+
+// loadSourceMap 读取映射文件, 文件不存在时返回空映射
+func loadSourceMap(path string) (sourceMap, error) {
+	sm := sourceMap{Version: smapVersion, Entries: make(map[string]smaprt.Site)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sm, nil
+	}
+
+	if err != nil {
+		return sm, err
+	}
+
+	if err := json.Unmarshal(data, &sm); err != nil {
+		return sm, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if sm.Version != smapVersion {
+		return sm, fmt.Errorf("%s: unsupported version %d", path, sm.Version)
+	}
+
+	if sm.Entries == nil {
+		sm.Entries = make(map[string]smaprt.Site)
+	}
+
+	return sm, nil
+}
+
+// marshalSourceMap 将映射序列化为稳定的 JSON(键有序、两空格缩进、末尾换行)
+func marshalSourceMap(sm sourceMap) []byte {
+	data, _ := json.MarshalIndent(sm, "", "  ")
+
+	return append(data, '\n')
+}
+
+// mergeSourceMap 以 siteRegistry 替换 sm 中位于 scopes 范围内的条目, 范围外的条目保持不变
+func mergeSourceMap(sm sourceMap, scopes []string) sourceMap {
+	merged := sourceMap{Version: smapVersion, Entries: make(map[string]smaprt.Site, len(sm.Entries)+len(siteRegistry))}
+
+	for tok, loc := range sm.Entries {
+		if !inSmapScope(loc.File, scopes) {
+			merged.Entries[tok] = loc
+		}
+	}
+
+	for tok, loc := range siteRegistry {
+		merged.Entries[tok] = loc
+	}
+
+	return merged
+}
+
+// writeSourceMap 合并并写出映射文件, 仅在内容变化时输出 [SMAP]; dry-run 下只输出不写回
+func writeSourceMap(scopes []string, baseDir string) error {
+	if !smapEnabled() {
+		return nil
+	}
+
+	path := smapPath(baseDir)
+
+	sm, err := loadSourceMap(path)
+	if err != nil {
+		return err
+	}
+
+	data := marshalSourceMap(mergeSourceMap(sm, scopes))
+
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+
+	fmt.Printf("[SMAP] %s: %d entries\n", relPath(path, baseDir), len(siteRegistry))
+
+	if !*writeFlg {
+		return nil
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// verifySourceMap 校验映射文件与源码一致: scopes 范围内每个令牌都应存在且位置正确, 且不应残留已失效的令牌。
+// 返回问题描述列表
+func verifySourceMap(scopes []string, baseDir string) []string {
+	if !smapEnabled() {
+		return nil
+	}
+
+	path := smapPath(baseDir)
+	rel := relPath(path, baseDir)
+
+	sm, err := loadSourceMap(path)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", rel, err)}
+	}
+
+	var issues []string
+
+	for tok, loc := range siteRegistry {
+		got, ok := sm.Entries[tok]
+
+		switch {
+		case !ok:
+			issues = append(issues, fmt.Sprintf("%s: missing token %s for %s", rel, tok, loc))
+		case got != loc:
+			issues = append(issues, fmt.Sprintf("%s: token %s maps to '%s' expected '%s'", rel, tok, got, loc))
+		}
+	}
+
+	for tok, loc := range sm.Entries {
+		if _, ok := siteRegistry[tok]; !ok && inSmapScope(loc.File, scopes) {
+			issues = append(issues, fmt.Sprintf("%s: stale token %s for %s", rel, tok, loc))
+		}
+	}
+
+	sort.Strings(issues)
+
+	return issues
+}
@@ -0,0 +1,166 @@
+//
+// FilePath    : zap-smap\smap_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : -token 模式、smap.json 与 decode 子命令单测
+//
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+// TestMain_Token_WriteAndVerify 测试 -token 注入令牌并生成 smap.json, -verify 同时校验令牌与映射文件
+func TestMain_Token_WriteAndVerify(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "go.mod", "module example.com/app\n")
+	writeFile(t, td, "a.go", `package app
+
+import "go.uber.org/zap"
+
+func Foo() {
+	zap.L().Info("hello")
+}
+`)
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*tokenFlg = true
+	*tokenKeyFlg = "secret"
+	os.Args = []string{"cmd"}
+
+	out := captureOutput(func() { main() })
+	if !strings.Contains(out, "[SMAP] smap.json: 1 entries") {
+		t.Fatalf("expected smap written, got:\n%s", out)
+	}
+
+	tok := siteToken("a.go", 6, "example.com/app.Foo")
+
+	b, err := os.ReadFile(filepath.Join(td, "a.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	if !strings.Contains(string(b), `zap.L().Info("hello", zap.String("fl", "`+tok+`"))`) {
+		t.Fatalf("expected token %s injected, got:\n%s", tok, b)
+	}
+
+	sm, err := loadSourceMap(filepath.Join(td, "smap.json"))
+	if err != nil {
+		t.Fatalf("load smap: %v", err)
+	}
+
+	if got := sm.Entries[tok]; got != (smaprt.Site{File: "a.go", Line: 6, Func: "example.com/app.Foo"}) {
+		t.Fatalf("unexpected smap entry for %s: %+v", tok, sm.Entries)
+	}
+
+	// 第二次运行结果一致, 不输出 [PATCH]/[SMAP]
+	out = captureOutput(func() { main() })
+	if strings.Contains(out, "[PATCH]") || strings.Contains(out, "[SMAP]") {
+		t.Fatalf("expected idempotent run, got:\n%s", out)
+	}
+
+	*writeFlg = false
+	*verifyFlg = true
+
+	out = captureOutput(func() { main() })
+	if !strings.Contains(out, "smap: 0") || !strings.Contains(out, "All injections look correct.") {
+		t.Fatalf("expected clean verify, got:\n%s", out)
+	}
+
+	// 映射文件缺失条目或残留失效条目时 verify 报告问题
+	writeFile(t, td, "smap.json", `{"version":1,"entries":{"0123456789abcdef":{"file":"a.go","line":1}}}`)
+
+	out = captureOutput(func() { main() })
+	if !strings.Contains(out, "smap: 2") || !strings.Contains(out, "missing token "+tok) || !strings.Contains(out, "stale token 0123456789abcdef") {
+		t.Fatalf("expected missing and stale smap issues, got:\n%s", out)
+	}
+	if strings.Contains(out, "All injections look correct.") {
+		t.Fatalf("expected verify failure, got:\n%s", out)
+	}
+}
+
+// TestMain_Token_KeepsEntriesOutsideScope 测试单文件运行只替换该文件的条目, 其余条目保留
+func TestMain_Token_KeepsEntriesOutsideScope(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "smap.json", `{"version":1,"entries":{"0123456789abcdef":{"file":"other.go","line":3},"fedcba9876543210":{"file":"a.go","line":1}}}`)
+	writeFile(t, td, "a.go", `package app
+
+import "go.uber.org/zap"
+
+func Foo() {
+	zap.L().Warn("w")
+}
+`)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(td); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	*pathFlag = "a.go"
+	*writeFlg = true
+	*tokenFlg = true
+	*tokenKeyFlg = "secret"
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	sm, err := loadSourceMap(filepath.Join(td, "smap.json"))
+	if err != nil {
+		t.Fatalf("load smap: %v", err)
+	}
+
+	if _, ok := sm.Entries["0123456789abcdef"]; !ok {
+		t.Fatalf("expected entry outside scope kept, got %+v", sm.Entries)
+	}
+	if _, ok := sm.Entries["fedcba9876543210"]; ok {
+		t.Fatalf("expected stale entry in scope removed, got %+v", sm.Entries)
+	}
+	if len(sm.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", sm.Entries)
+	}
+}
+
+// TestRunDecode 测试 decode 还原 JSON 与 console 日志中的令牌, 未知令牌保持原样
+func TestRunDecode(t *testing.T) {
+	td := t.TempDir()
+	writeFile(t, td, "smap.json", `{"version":1,"entries":{"0123456789abcdef":{"file":"svc/a.go","line":12,"func":"app/svc.Run"}}}`)
+
+	in := strings.NewReader(strings.Join([]string{
+		`{"level":"info","msg":"hi","fl":"0123456789abcdef"}`,
+		"INFO\thi\t{\"fl\": \"0123456789abcdef\", \"id\": \"ffffffffffffffff\"}",
+		`2026-01-01 INFO hi fl=0123456789abcdef`,
+	}, "\n"))
+
+	var out bytes.Buffer
+	if err := runDecode([]string{"-smap", filepath.Join(td, "smap.json")}, in, &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	want := strings.Join([]string{
+		`{"level":"info","msg":"hi","fl":"svc/a.go:12 | app/svc.Run"}`,
+		"INFO\thi\t{\"fl\": \"svc/a.go:12 | app/svc.Run\", \"id\": \"ffffffffffffffff\"}",
+		`2026-01-01 INFO hi fl=svc/a.go:12 | app/svc.Run`,
+	}, "\n") + "\n"
+
+	if out.String() != want {
+		t.Fatalf("unexpected decode output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,209 @@
+//
+// FilePath    : zap-smap\smaptest\smaptest.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 单测辅助包, 校验捕获的日志条目都带有有效的注入位置
+//
+
+// Package smaptest 为使用 zap-smap 的项目提供单测辅助: 以 zaptest/observer 捕获日志,
+// 测试结束时逐条校验注入字段, 发现忘记运行 zap-smap -write 的日志调用。
+//
+//	func TestCreate(t *testing.T) {
+//		logger, _ := smaptest.NewLogger(t)
+//		svc := order.New(logger)
+//		...
+//	}
+//
+// 以下情况会使测试失败:
+//   - 条目不带注入字段
+//   - 注入的文件在模块中不存在
+//   - 注入的行上没有 zap 日志调用
+package smaptest
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	smaprt "github.com/jiaopengzi/zap-smap/runtime"
+)
+
+// logMethods 注入位置所在行应当出现的 zap 方法, 与 zap-smap 注入时的位置一致(调用的左括号)
+var logMethods = map[string]bool{
+	"Debug": true, "Info": true, "Warn": true, "Error": true,
+	"DPanic": true, "Panic": true, "Fatal": true, "Log": true, "Check": true,
+}
+
+// config 校验配置
+type config struct {
+	key  string
+	root string
+}
+
+// Option 校验的可选配置
+type Option func(*config)
+
+// Key 设置注入字段的键, 默认 fl
+func Key(key string) Option {
+	return func(c *config) {
+		c.key = key
+	}
+}
+
+// Root 设置注入路径所相对的目录, 默认为从当前目录向上查找到的模块根(go.mod 所在目录)
+func Root(dir string) Option {
+	return func(c *config) {
+		c.root = dir
+	}
+}
+
+// NewCore 创建 observer core, 测试结束时以 Check 校验捕获的全部条目
+func NewCore(t testing.TB, enab zapcore.LevelEnabler, opts ...Option) (zapcore.Core, *observer.ObservedLogs) {
+	t.Helper()
+
+	core, logs := observer.New(enab)
+
+	t.Cleanup(func() {
+		Check(t, logs, opts...)
+	})
+
+	return core, logs
+}
+
+// NewLogger 创建捕获全部级别的 logger, 测试结束时以 Check 校验捕获的全部条目
+func NewLogger(t testing.TB, opts ...Option) (*zap.Logger, *observer.ObservedLogs) {
+	t.Helper()
+
+	core, logs := NewCore(t, zapcore.DebugLevel, opts...)
+
+	return zap.New(core), logs
+}
+
+// Check 校验 logs 中的每个条目都带有指向模块中 zap 日志调用的注入字段, 不符合时以 t.Errorf 报告
+func Check(t testing.TB, logs *observer.ObservedLogs, opts ...Option) {
+	t.Helper()
+
+	cfg := config{key: smaprt.DefaultKey}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.root == "" {
+		root, err := moduleRoot()
+		if err != nil {
+			t.Errorf("smaptest: %v", err)
+			return
+		}
+
+		cfg.root = root
+	}
+
+	files := make(map[string]map[int]bool)
+
+	for _, e := range logs.All() {
+		if err := checkEntry(e, cfg, files); err != nil {
+			t.Errorf("smaptest: entry %q: %v", e.Message, err)
+		}
+	}
+}
+
+// checkEntry 校验单个条目, files 缓存已解析文件中日志调用所在的行
+func checkEntry(e observer.LoggedEntry, cfg config, files map[string]map[int]bool) error {
+	v, ok := fieldValue(e.Context, cfg.key)
+	if !ok {
+		return fmt.Errorf("missing field %q, run zap-smap -write", cfg.key)
+	}
+
+	site, ok := smaprt.ParseSite(v)
+	if !ok {
+		return fmt.Errorf("field %q has unrecognized value %q", cfg.key, v)
+	}
+
+	lines, ok := files[site.File]
+	if !ok {
+		var err error
+		if lines, err = logLines(filepath.Join(cfg.root, filepath.FromSlash(site.File))); err != nil {
+			return fmt.Errorf("%s: %w", site, err)
+		}
+
+		files[site.File] = lines
+	}
+
+	if !lines[site.Line] {
+		return fmt.Errorf("%s: no logging call at line %d, run zap-smap -write", site, site.Line)
+	}
+
+	return nil
+}
+
+// fieldValue 返回 fields 中键为 key 的字符串字段的值
+func fieldValue(fields []zapcore.Field, key string) (string, bool) {
+	for _, f := range fields {
+		if f.Key == key && f.Type == zapcore.StringType {
+			return f.String, true
+		}
+	}
+
+	return "", false
+}
+
+// logLines 解析文件, 返回 zap 日志调用左括号所在的行
+func logLines(path string) (map[int]bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("file does not exist in module")
+	}
+
+	fSet := token.NewFileSet()
+
+	file, err := parser.ParseFile(fSet, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[int]bool)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		if sel, ok := ce.Fun.(*ast.SelectorExpr); ok && logMethods[sel.Sel.Name] {
+			lines[fSet.Position(ce.Lparen).Line] = true
+		}
+
+		return true
+	})
+
+	return lines, nil
+}
+
+// moduleRoot 从当前目录向上查找 go.mod 所在目录
+func moduleRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("go.mod not found, use smaptest.Root")
+		}
+
+		dir = parent
+	}
+}
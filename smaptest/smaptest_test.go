@@ -0,0 +1,128 @@
+//
+// FilePath    : zap-smap\smaptest\smaptest_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : smaptest 单测
+//
+
+package smaptest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// sample 单测使用的源码, Info 调用位于第 6 行, Check 调用位于第 8 行
+const sample = `package svc
+
+import "go.uber.org/zap"
+
+func Run(l *zap.Logger) {
+	l.Info("started", zap.String("fl", "svc/a.go:6"))
+
+	if ce := l.Check(zap.WarnLevel, "slow"); ce != nil {
+		ce.Write(zap.String("fl", "svc/a.go:8"))
+	}
+}
+`
+
+// recorder 记录 Errorf 的 testing.TB
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+// Helper 实现 testing.TB
+func (r *recorder) Helper() {}
+
+// Errorf 实现 testing.TB
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+// writeRepo 创建包含 svc/a.go 的目录
+func writeRepo(t *testing.T) string {
+	t.Helper()
+
+	td := t.TempDir()
+	if err := os.Mkdir(filepath.Join(td, "svc"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(td, "svc", "a.go"), []byte(sample), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	return td
+}
+
+// TestNewLogger_ValidSites 测试注入位置有效时测试通过, With 添加的字段同样被识别
+func TestNewLogger_ValidSites(t *testing.T) {
+	td := writeRepo(t)
+
+	logger, logs := NewLogger(t, Root(td))
+	logger.Info("started", zap.String("fl", "svc/a.go:6"))
+	logger.With(zap.String("fl", "svc/a.go:8 | example.com/app/svc.Run")).Warn("slow")
+
+	if logs.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", logs.Len())
+	}
+}
+
+// TestCheck_ReportsInvalidSites 测试缺少字段、文件不存在、行上没有日志调用与自定义字段键
+func TestCheck_ReportsInvalidSites(t *testing.T) {
+	td := writeRepo(t)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	logger.Info("missing")
+	logger.Info("gone", zap.String("fl", "svc/gone.go:3"))
+	logger.Info("stale", zap.String("fl", "svc/a.go:7"))
+	logger.Info("token", zap.String("fl", "3f9a0c1d2e4b5a67"))
+	logger.Info("ok", zap.String("fl", "svc/a.go:6"))
+	logger.Info("custom", zap.String("loc", "svc/a.go:8"))
+
+	r := &recorder{}
+	Check(r, logs, Root(td))
+
+	wants := []string{
+		`smaptest: entry "missing": missing field "fl", run zap-smap -write`,
+		`smaptest: entry "gone": svc/gone.go:3: file does not exist in module`,
+		`smaptest: entry "stale": svc/a.go:7: no logging call at line 7, run zap-smap -write`,
+		`smaptest: entry "token": field "fl" has unrecognized value "3f9a0c1d2e4b5a67"`,
+		`smaptest: entry "custom": missing field "fl", run zap-smap -write`,
+	}
+
+	if got := strings.Join(r.errs, "\n"); got != strings.Join(wants, "\n") {
+		t.Fatalf("unexpected errors:\n%s\nwant:\n%s", got, strings.Join(wants, "\n"))
+	}
+
+	r = &recorder{}
+	Check(r, logs.FilterMessage("custom"), Root(td), Key("loc"))
+
+	if len(r.errs) != 0 {
+		t.Fatalf("expected custom key accepted, got %v", r.errs)
+	}
+}
+
+// TestModuleRoot 测试从当前目录向上查找模块根
+func TestModuleRoot(t *testing.T) {
+	root, err := moduleRoot()
+	if err != nil {
+		t.Fatalf("module root: %v", err)
+	}
+
+	wd, _ := os.Getwd()
+	if root != filepath.Dir(wd) {
+		t.Fatalf("expected %s, got %s", filepath.Dir(wd), root)
+	}
+}
@@ -3,7 +3,7 @@
 // Author      : jiaopengzi
 // Blog        : https://jiaopengzi.com
 // Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
-// Description : 对 zap 字段进行排序
+// Description : 对日志字段进行排序
 //
 
 package main
@@ -13,58 +13,57 @@ import (
 	"sort"
 )
 
-// sortZapFields 将 ce 的 zap 字段按 key 的字母顺序排序, 保留第一个参数(通常是 message)，
-// 其它非 zap 字段保留在尾部(原序)
-func sortZapFields(ce *ast.CallExpr) {
-	if len(ce.Args) <= 1 {
+// sortFields 将 lc 的字段按 key 的字母顺序排序, fieldStart 之前的参数(通常是 message)不参与排序，
+// 可排序的字段为 sortFuncs 中的构造调用与 "key", value 键值对(整体移动), 其它字段保留在尾部(原序)
+func sortFields(lc logCall) {
+	exprs := lc.fields()
+	if len(exprs) <= 1 {
 		return
 	}
 
-	head := ce.Args[0]
+	// 带 key 的临时切片以便排序
+	type kv struct {
+		exprs []ast.Expr
+		key   string
+	}
 
-	var zapExprs []ast.Expr
+	var tmp []kv
 
 	var others []ast.Expr
 
-	for _, a := range ce.Args[1:] {
-		if call, ok := isZapFieldCall(a); ok {
-			zapExprs = append(zapExprs, call)
+	for _, f := range parseFields(lc) {
+		group := exprs[f.index : f.index+f.width]
+
+		if isSortableField(lc.b, f) {
+			tmp = append(tmp, kv{exprs: group, key: f.key})
 		} else {
-			others = append(others, a)
+			others = append(others, group...)
 		}
 	}
 
-	if len(zapExprs) <= 1 {
-		// 没有或只有一个 zap 字段, 无需排序
-		ce.Args = append([]ast.Expr{head}, append(zapExprs, others...)...)
-		return
-	}
+	sort.SliceStable(tmp, func(i, j int) bool {
+		return tmp[i].key < tmp[j].key
+	})
 
-	// 带 key 的临时切片以便排序
-	type kv struct {
-		expr ast.Expr
-		key  string
+	sorted := make([]ast.Expr, 0, len(exprs))
+	for _, it := range tmp {
+		sorted = append(sorted, it.exprs...)
 	}
 
-	tmp := make([]kv, 0, len(zapExprs))
+	lc.setFields(append(sorted, others...))
+}
 
-	for _, e := range zapExprs {
-		if c, ok := e.(*ast.CallExpr); ok {
-			k := getZapFieldKey(c)
-			tmp = append(tmp, kv{expr: e, key: k})
-		} else {
-			tmp = append(tmp, kv{expr: e, key: ""})
-		}
+// isSortableField 判断字段是否参与排序: sortFuncs 中的构造调用(例如 zap.String/Any/Uint64)或键值对
+func isSortableField(b *backend, f fieldRef) bool {
+	if f.isPair() {
+		return true
 	}
 
-	sort.Slice(tmp, func(i, j int) bool {
-		return tmp[i].key < tmp[j].key
-	})
-
-	sorted := make([]ast.Expr, 0, len(tmp))
-	for _, it := range tmp {
-		sorted = append(sorted, it.expr)
+	if f.call == nil {
+		return false
 	}
 
-	ce.Args = append([]ast.Expr{head}, append(sorted, others...)...)
+	_, name, _ := b.fieldCall(f.call)
+
+	return b.sortFuncs[name]
 }
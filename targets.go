@@ -0,0 +1,204 @@
+//
+// FilePath    : zap-smap\targets.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 位置参数模式, 按 go 风格的包模式(./...、./pkg/...、导入路径)或文件列表一次处理多个目标
+//
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fileTargets 位置参数解析得到的处理范围
+type fileTargets struct {
+	files  []string // 去重并排序后的待处理文件
+	scopes []string // 各参数覆盖的相对路径范围(见 inSmapScope), 用于清理映射文件中已失效的条目
+}
+
+// resolveTargets 将位置参数解析为待处理的文件, 每个参数可以是:
+//   - 文件路径
+//   - 目录或以 ... 结尾的目录模式: 目录只包含其中的文件, ./pkg/... 递归包含子目录(不进入嵌套的 module)
+//   - 主模块或 go.work 中 module 的导入路径, 同样支持 /... 后缀
+//
+// 同一文件只出现一次, 跳过规则与目录遍历一致
+func resolveTargets(args []string, modulePath, baseDir string) (*fileTargets, error) {
+	ft := &fileTargets{}
+	seen := make(map[string]bool)
+
+	add := func(path string) {
+		abs := absSlash(path)
+		if seen[abs] || shouldSkipFile(path) {
+			return
+		}
+
+		seen[abs] = true
+		ft.files = append(ft.files, filepath.Clean(path))
+	}
+
+	for _, arg := range args {
+		dir, recursive := strings.CutSuffix(filepath.ToSlash(arg), "/...")
+		if arg == "..." {
+			dir, recursive = ".", true
+		}
+
+		path, err := targetPath(filepath.FromSlash(dir), modulePath, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+
+		switch {
+		case !fi.IsDir():
+			if recursive {
+				return nil, fmt.Errorf("%s: %s is not a directory", arg, dir)
+			}
+
+			ft.scopes = append(ft.scopes, smapScope(path, baseDir))
+			add(path)
+		case recursive:
+			ft.scopes = append(ft.scopes, smapScope(path, baseDir))
+			err = walkTargetDir(path, add)
+		default:
+			ft.scopes = append(ft.scopes, smapScope(path, baseDir)+"/*")
+			err = readTargetDir(path, add)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(ft.files)
+
+	return ft, nil
+}
+
+// targetPath 返回参数对应的路径: 磁盘上存在的路径原样返回, 否则按导入路径映射到 module 目录
+func targetPath(arg, modulePath, baseDir string) (string, error) {
+	if _, err := os.Stat(arg); err == nil || filepath.IsAbs(arg) || strings.HasPrefix(filepath.ToSlash(arg), ".") {
+		return arg, nil
+	}
+
+	importPath := filepath.ToSlash(arg)
+
+	// 在主模块与 go.work 中的 module 里选择 module path 最长的匹配
+	modules := map[string]string{modulePath: baseDir}
+
+	if ws := findWorkspace(absDir(baseDir)); ws != nil {
+		for dir := range ws.uses {
+			modules[readModulePath(dir)] = dir
+		}
+	}
+
+	best := ""
+
+	for mp := range modules {
+		if mp != "" && (importPath == mp || strings.HasPrefix(importPath, mp+"/")) && len(mp) > len(best) {
+			best = mp
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("not a file, directory or package in the main module")
+	}
+
+	return filepath.Join(modules[best], filepath.FromSlash(strings.TrimPrefix(importPath[len(best):], "/"))), nil
+}
+
+// readTargetDir 添加目录中的文件, 不包含子目录
+func readTargetDir(dir string, add func(string)) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			add(filepath.Join(dir, e.Name()))
+		}
+	}
+
+	return nil
+}
+
+// walkTargetDir 递归添加目录中的文件, 与 go 的 ./... 一致不进入嵌套的 module(含 go.mod 的子目录)
+func walkTargetDir(root string, add func(string)) error {
+	inModule := findModuleRoot(absDir(root)).dir != ""
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			add(path)
+			return nil
+		}
+
+		if path == root {
+			return nil
+		}
+
+		if shouldSkipDir(path) {
+			return filepath.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil && inModule {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+}
+
+// runTargetsMode 处理位置参数解析得到的全部文件, 与目录模式一样只输出一次汇总
+func runTargetsMode(args []string, fSet *token.FileSet, modulePath, baseDir string) error {
+	ft, err := resolveTargets(args, modulePath, baseDir)
+	if err != nil {
+		return err
+	}
+
+	if *verifyFlg {
+		return verifyTargets(ft, fSet, modulePath, baseDir)
+	}
+
+	planTypeCheck(ft.files)
+
+	for _, path := range ft.files {
+		if err := patchFile(path, fSet, modulePath, baseDir); err != nil {
+			return err
+		}
+	}
+
+	return writeGenerated(ft.scopes, modulePath, baseDir)
+}
+
+// verifyTargets 校验全部文件并打印合并的汇总
+func verifyTargets(ft *fileTargets, fSet *token.FileSet, modulePath, baseDir string) error {
+	var sum verifyResult
+
+	var issueFiles []string
+
+	for _, path := range ft.files {
+		vr, files := verifyWalkFile(path, fSet, modulePath, baseDir)
+		sum.add(vr)
+		issueFiles = append(issueFiles, files...)
+	}
+
+	finishVerify(sum, issueFiles, ft.scopes, modulePath, baseDir)
+
+	return nil
+}
@@ -0,0 +1,132 @@
+//
+// FilePath    : zap-smap\targets_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 位置参数模式单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// targetsSample 目标单测使用的源码, Info 调用位于第 6 行
+const targetsSample = "package x\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n\tzap.L().Info(\"run\")\n}\n"
+
+// targetsFiles 包含多个包与嵌套 module(tools)的仓库, 每个文件有一条日志调用(第 6 行)
+var targetsFiles = map[string]string{
+	"go.mod":          "module example.com/app\n",
+	"svc/a.go":        targetsSample,
+	"svc/sub/b.go":    targetsSample,
+	"cmd/api/main.go": targetsSample,
+	"tools/go.mod":    "module example.com/tools\n",
+	"tools/t.go":      targetsSample,
+}
+
+// TestResolveTargets 测试包模式、导入路径与文件参数的解析、去重以及嵌套 module 的处理
+func TestResolveTargets(t *testing.T) {
+	td := writeTree(t, targetsFiles)
+
+	resetGlobals()
+	defer resetGlobals()
+
+	*relToFlg = relToModule
+
+	t.Chdir(td)
+
+	cases := []struct {
+		args   []string
+		files  string
+		scopes string
+	}{
+		{[]string{"./..."}, "cmd/api/main.go,svc/a.go,svc/sub/b.go", "."},
+		{[]string{"./svc", "svc/a.go", "example.com/app/svc/..."}, "svc/a.go,svc/sub/b.go", "svc/*,svc/a.go,svc"},
+		{[]string{"example.com/app/cmd/api", "./tools"}, "cmd/api/main.go,tools/t.go", "cmd/api/*,./*"},
+	}
+
+	for _, c := range cases {
+		ft, err := resolveTargets(c.args, "example.com/app", ".")
+		if err != nil {
+			t.Fatalf("%v: %v", c.args, err)
+		}
+
+		files := make([]string, len(ft.files))
+		for i, f := range ft.files {
+			files[i] = filepath.ToSlash(f)
+		}
+
+		if got := strings.Join(files, ","); got != c.files {
+			t.Fatalf("%v: files %s, want %s", c.args, got, c.files)
+		}
+
+		if got := strings.Join(ft.scopes, ","); got != c.scopes {
+			t.Fatalf("%v: scopes %s, want %s", c.args, got, c.scopes)
+		}
+	}
+
+	for _, args := range [][]string{{"example.com/other"}, {"./missing/..."}, {"svc/a.go/..."}} {
+		if _, err := resolveTargets(args, "example.com/app", "."); err == nil {
+			t.Fatalf("%v: expected error", args)
+		}
+	}
+}
+
+// TestInSmapScope 测试递归范围与只含目录中文件的范围
+func TestInSmapScope(t *testing.T) {
+	cases := []struct {
+		file, scopes string
+		want         bool
+	}{
+		{"svc/a.go", ".", true},
+		{"svc/a.go", "svc", true},
+		{"svc/sub/b.go", "svc/*", false},
+		{"svc/a.go", "svc/*", true},
+		{"a.go", "./*", true},
+		{"svc/a.go", "cmd,svc/a.go", true},
+		{"svc/b.go", "cmd,svc/a.go", false},
+	}
+
+	for _, c := range cases {
+		if got := inSmapScope(c.file, strings.Split(c.scopes, ",")); got != c.want {
+			t.Fatalf("inSmapScope(%q, %q) = %v, want %v", c.file, c.scopes, got, c.want)
+		}
+	}
+}
+
+// TestRunTargetsMode 测试多个位置参数在一次运行中处理并输出一份汇总
+func TestRunTargetsMode(t *testing.T) {
+	td := writeTree(t, targetsFiles)
+
+	resetGlobals()
+	defer resetGlobals()
+
+	t.Chdir(td)
+
+	*writeFlg = true
+	*fieldFlg = "fl"
+	os.Args = []string{"cmd", "./svc/...", "./cmd/api"}
+
+	_ = captureOutput(func() { main() })
+
+	for name, want := range map[string]bool{"svc/a.go": true, "svc/sub/b.go": true, "cmd/api/main.go": true, "tools/t.go": false} {
+		b, _ := os.ReadFile(filepath.Join(td, name))
+		if got := strings.Contains(string(b), `zap.String("fl", "`+name+`:6")`); got != want {
+			t.Fatalf("%s: injected %v, want %v\n%s", name, got, want, b)
+		}
+	}
+
+	*writeFlg = false
+	*verifyFlg = true
+	os.Args = []string{"cmd", "./svc/...", "./cmd/api"}
+
+	out := captureOutput(func() { main() })
+
+	if strings.Count(out, "VERIFY SUMMARY") != 1 || !strings.Contains(out, "total calls: 3") {
+		t.Fatalf("expected one combined summary, got:\n%s", out)
+	}
+}
@@ -56,6 +56,50 @@ func writeFile(t *testing.T, dir, name, content string) {
 	}
 }
 
+// writeTree 在新的临时目录中按 相对路径 → 内容 创建文件(自动创建上层目录), 以 '/' 结尾的路径只创建目录;
+// 多个 map 依次写入, 返回临时目录
+func writeTree(t *testing.T, trees ...map[string]string) string {
+	t.Helper()
+
+	td := t.TempDir()
+
+	for _, tree := range trees {
+		for name, content := range tree {
+			p := filepath.Join(td, filepath.FromSlash(name))
+
+			dir := filepath.Dir(p)
+			if strings.HasSuffix(name, "/") {
+				dir = p
+			}
+
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+
+			if !strings.HasSuffix(name, "/") {
+				writeFile(t, dir, filepath.Base(p), content)
+			}
+		}
+	}
+
+	return td
+}
+
+// zapModule 返回 module example.com/app 的 go.mod 与本仓库的 go.sum, 使 go 命令可以离线从模块缓存解析 zap
+func zapModule(t *testing.T) map[string]string {
+	t.Helper()
+
+	sum, err := os.ReadFile("go.sum")
+	if err != nil {
+		t.Fatalf("read go.sum: %v", err)
+	}
+
+	return map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.25\n\nrequire go.uber.org/zap v1.27.1\n\nrequire go.uber.org/multierr v1.11.0 // indirect\n",
+		"go.sum": string(sum),
+	}
+}
+
 // resetGlobals 重置全局变量, 以避免测试间相互影响
 func resetGlobals() {
 	*pathFlag = "."
@@ -65,7 +109,39 @@ func resetGlobals() {
 	*verifyFlg = false
 	*excludeFlag = ""
 	*delFlg = ""
-	excludeList = nil
+	*policyFlg = ""
+	*backendsFlg = ""
+	*tokenFlg = false
+	*tokenKeyFlg = ""
+	*smapFlg = "smap.json"
+	*locsFlg = false
+	*garbleFlg = false
+	*gogarbleFlg = ""
+	*gStripFlg = false
+	*forFlg = ""
+	*relToFlg = relToPath
+	*tagsFlg = ""
+	garblePatterns = nil
+	forFiles = nil
+	moduleRoots = nil
+	workspaces = nil
+	repoRoots = nil
+	siteRegistry = nil
+	tokenKey = nil
+	locTables = nil
+	*includeFlag = ""
+	*defaultsFlg = defaultExcludes
+	*ignoreFlg = true
+	*testsFlg = testsInclude
+	skipConf = nil
+	*contFlg = false
+	failures = nil
+	*typeChkFlg = true
+	*forceFlg = false
+	typeCheckers = nil
+	typeCheckDirs = nil
+	levelPolicy = nil
+	activeBackends = builtinBackends
 }
 
 // reset newly added flags
@@ -0,0 +1,254 @@
+//
+// FilePath    : zap-smap\typecheck.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 写回前对改写后的包做类型检查, 拒绝写回无法编译的输出
+//
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// typeCheckCache 一个 module 的导入器与已执行 go list 的包目录
+type typeCheckCache struct {
+	listed  map[string]bool   // 已执行 go list 的包目录
+	exports map[string]string // 导入路径 → 导出数据文件
+	imp     types.Importer
+}
+
+var (
+	// typeCheckers 按 module 根目录缓存的导入器, 同一 module 中的包共用一次 go list 的结果
+	typeCheckers map[string]*typeCheckCache
+
+	// typeCheckDirs 本次运行待处理文件所在的包目录, 由 planTypeCheck 设置
+	typeCheckDirs []string
+)
+
+// planTypeCheck 登记本次运行待处理的文件, 首次对某个 module 做类型检查时一次性为其中全部待处理的包执行 go list
+func planTypeCheck(files []string) {
+	if !*typeChkFlg {
+		return
+	}
+
+	seen := make(map[string]bool)
+	typeCheckDirs = nil
+
+	for _, f := range files {
+		if dir := filepath.Dir(f); !seen[dir] {
+			seen[dir] = true
+			typeCheckDirs = append(typeCheckDirs, dir)
+		}
+	}
+}
+
+// typeCheckKey 返回包目录 dir 所属 module 的根目录, 不属于任何 module 时为 dir 本身
+func typeCheckKey(dir string) string {
+	if mr := findModuleRoot(absDir(dir)); mr.dir != "" {
+		return mr.dir
+	}
+
+	return dir
+}
+
+// checkImporter 返回包目录 dir 的导入器, 按导入路径读取 exportFiles 得到的编译器导出类型信息。
+// dir 尚未执行 go list 时与同一 module 中其余已登记的包目录一起执行
+func checkImporter(dir string) types.Importer {
+	key := typeCheckKey(dir)
+
+	c, ok := typeCheckers[key]
+	if !ok {
+		c = &typeCheckCache{listed: make(map[string]bool), exports: make(map[string]string)}
+		c.imp = importer.ForCompiler(token.NewFileSet(), "gc", func(path string) (io.ReadCloser, error) {
+			if f, ok := c.exports[path]; ok {
+				return os.Open(f)
+			}
+
+			return nil, fmt.Errorf("no export data for %s", path)
+		})
+
+		if typeCheckers == nil {
+			typeCheckers = make(map[string]*typeCheckCache)
+		}
+
+		typeCheckers[key] = c
+	}
+
+	if c.listed[dir] {
+		return c.imp
+	}
+
+	dirs := []string{dir}
+
+	for _, d := range typeCheckDirs {
+		if d != dir && !c.listed[d] && typeCheckKey(d) == key {
+			dirs = append(dirs, d)
+		}
+	}
+
+	maps.Copy(c.exports, exportFiles(dirs...))
+
+	for _, d := range dirs {
+		c.listed[d] = true
+	}
+
+	return c.imp
+}
+
+// exportFiles 以 go list -e -export -deps 离线编译包目录 dirs 中各包的依赖(结果由 go 的构建缓存复用),
+// 返回 导入路径 → 导出数据文件; 只编译被处理的包的依赖, 不编译整个 module。构建标签与 -for 一致取自 -tags
+func exportFiles(dirs ...string) map[string]string {
+	exports := make(map[string]string)
+
+	args := []string{"list", "-e", "-export", "-deps", "-f", "{{if .Export}}{{.ImportPath}}={{.Export}}{{end}}"}
+	if *tagsFlg != "" {
+		args = append(args, "-tags", *tagsFlg)
+	}
+
+	for _, d := range dirs {
+		if abs, err := filepath.Abs(d); err == nil {
+			d = abs
+		}
+
+		args = append(args, d)
+	}
+
+	// GOPROXY=off 保证只使用模块缓存或 vendor 目录, 不访问网络; 失败时依赖无法解析, 改写前后的错误相同
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dirs[0]
+	cmd.Env = append(os.Environ(), "GOPROXY=off")
+
+	b, err := cmd.Output()
+	if err != nil {
+		return exports
+	}
+
+	for line := range strings.SplitSeq(string(b), "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			exports[k] = v
+		}
+	}
+
+	return exports
+}
+
+// typeCheckContext 返回选择同包文件使用的构建上下文, 与 go list 一致遵循 -tags 与环境变量 GOOS、GOARCH
+func typeCheckContext() *build.Context {
+	ctx := build.Default
+
+	if v := os.Getenv("GOOS"); v != "" {
+		ctx.GOOS = v
+	}
+
+	if v := os.Getenv("GOARCH"); v != "" {
+		ctx.GOARCH = v
+	}
+
+	for t := range strings.SplitSeq(*tagsFlg, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			ctx.BuildTags = append(ctx.BuildTags, t)
+		}
+	}
+
+	return &ctx
+}
+
+// typeCheckRewrite 对比 path 所在包改写前后的类型错误, 改写引入新错误时返回 opCheck 失败。
+// 依赖无法解析(例如模块缓存中没有)时相关错误在改写前后相同, 不会阻止写回
+func typeCheckRewrite(path string, original []byte, out string) error {
+	if !strings.HasSuffix(path, ".go") {
+		return nil
+	}
+
+	before := packageTypeErrors(path, original)
+	after := packageTypeErrors(path, []byte(out))
+
+	var added []string
+
+	for msg, n := range after {
+		if n > before[msg] {
+			added = append(added, msg)
+		}
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	sort.Strings(added)
+
+	return &fileError{op: opCheck, path: path, err: errors.New("rewritten package would not compile: " + strings.Join(added, "; "))}
+}
+
+// packageTypeErrors 以 src 作为 path 的内容对其所在包做类型检查, 返回错误信息(不含位置)及出现次数。
+// 同目录中包名相同且满足 typeCheckContext 构建约束的文件参与检查, path 不是 _test.go 时不包含 _test.go
+func packageTypeErrors(path string, src []byte) map[string]int {
+	fSet := token.NewFileSet()
+	errs := make(map[string]int)
+
+	file, err := parser.ParseFile(fSet, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		errs[err.Error()]++
+		return errs
+	}
+
+	files := []*ast.File{file}
+	dir := filepath.Dir(path)
+	isTest := strings.HasSuffix(path, "_test.go")
+
+	ctx := typeCheckContext()
+	entries, _ := os.ReadDir(dir)
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || filepath.Join(dir, name) == filepath.Clean(path) {
+			continue
+		}
+
+		if (!isTest && strings.HasSuffix(name, "_test.go")) || (locTables != nil && isLocsFile(name)) {
+			continue
+		}
+
+		if ok, err := ctx.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+
+		f, err := parser.ParseFile(fSet, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil || f.Name.Name != file.Name.Name {
+			continue
+		}
+
+		files = append(files, f)
+	}
+
+	conf := types.Config{
+		Importer:    checkImporter(dir),
+		FakeImportC: true,
+		Error: func(err error) {
+			// -locs 模式下引用的位置常量在全部文件处理完成后才写入常量表
+			if te, ok := err.(types.Error); ok && !(locTables != nil && strings.HasPrefix(te.Msg, "undefined: "+locIdentPrefix)) {
+				errs[strings.Join(strings.Fields(te.Msg), " ")]++
+			}
+		},
+	}
+
+	_, _ = conf.Check(file.Name.Name, fSet, files, nil)
+
+	return errs
+}
@@ -0,0 +1,235 @@
+//
+// FilePath    : zap-smap\typecheck_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : 写回前自校验单测: 行号修正不动点与类型检查
+//
+
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFixLineNumbers_ReachesFixpoint 测试行号修正后再次格式化的输出立即校验无误
+func TestFixLineNumbers_ReachesFixpoint(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	*fieldFlg = "fl"
+
+	td := t.TempDir()
+	src := "package app\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n\tzap.L().Info(\"a\", zap.String(\"fl\", \"a.go:1\"))\n}\n"
+	path := filepath.Join(td, "a.go")
+
+	out, err := fixLineNumbers(src, path, "example.com/app", td)
+	if err != nil {
+		t.Fatalf("fixLineNumbers: %v", err)
+	}
+
+	if !strings.Contains(out, `zap.String("fl", "a.go:6")`) {
+		t.Fatalf("expected corrected line, got:\n%s", out)
+	}
+
+	if again, _ := fixLineNumbers(out, path, "example.com/app", td); again != out {
+		t.Fatalf("expected fixpoint, got:\n%s", again)
+	}
+}
+
+// TestTypeCheck_RefusesBrokenRewrite 测试改写会引入编译错误时输出警告并保持文件不变, 关闭 -typecheck 时照常写回
+func TestTypeCheck_RefusesBrokenRewrite(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "shadowed zap",
+			body: "\ntype fakeLogger struct{}\n\nfunc (fakeLogger) Info(msg string) {}\n\ntype fake struct{}\n\nfunc (fake) L() fakeLogger { return fakeLogger{} }\n\nfunc Run() {\n\tzap := fake{}\n\tzap.L().Info(\"shadowed\")\n}\n",
+			want: "zap.String undefined",
+		},
+		{
+			name: "append on []any",
+			body: "\nfunc Run(extra []any) {\n\tzap.L().Info(\"args\", extra...)\n}\n",
+			want: "cannot use extra (variable of type []any) as []zap.Field value",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, check := range []bool{true, false} {
+				resetGlobals()
+
+				td := writeTree(t, zapModule(t), map[string]string{"a.go": "package app\n\nimport \"go.uber.org/zap\"\n" + c.body})
+				path := filepath.Join(td, "a.go")
+				original, _ := os.ReadFile(path)
+
+				*writeFlg = true
+				*fieldFlg = "fl"
+				*typeChkFlg = check
+
+				var err error
+
+				out := captureOutput(func() { err = patchFile(path, token.NewFileSet(), "example.com/app", td) })
+
+				got, _ := os.ReadFile(path)
+
+				if check && (err != nil || !strings.Contains(out, "warn: type-check") || !strings.Contains(out, c.want) || string(got) != string(original)) {
+					t.Fatalf("expected write refused with %q, got err=%v\n%s\n%s", c.want, err, out, got)
+				}
+
+				if !check && (err != nil || !strings.Contains(string(got), `zap.String("fl"`)) {
+					t.Fatalf("expected write without -typecheck, got err=%v\n%s", err, got)
+				}
+			}
+
+			resetGlobals()
+		})
+	}
+}
+
+// TestTypeCheck_RefusalKeepsWalking 测试 -locs 模式下被拒绝的包保持不变, 其余包照常写回并生成位置常量表
+func TestTypeCheck_RefusalKeepsWalking(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	pkg := func(name, body string) string {
+		return "package " + name + "\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n" + body + "}\n"
+	}
+
+	shadowed := "\tzap := struct{ L func() struct{ Info func(string) } }{}\n\tzap.L().Info(\"shadowed\")\n"
+
+	td := writeTree(t, zapModule(t), map[string]string{
+		"a/a.go": pkg("a", "\tzap.L().Info(\"a\")\n"),
+		"b/b.go": pkg("b", shadowed),
+		"c/c.go": pkg("c", "\tzap.L().Info(\"c\")\n"),
+	})
+
+	original, _ := os.ReadFile(filepath.Join(td, "b", "b.go"))
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*locsFlg = true
+	os.Args = []string{"cmd"}
+
+	out := captureOutput(func() { main() })
+
+	if !strings.Contains(out, "warn: type-check "+filepath.ToSlash(filepath.Join(td, "b", "b.go"))) {
+		t.Fatalf("expected refusal warning for b, got:\n%s", out)
+	}
+
+	if b, _ := os.ReadFile(filepath.Join(td, "b", "b.go")); string(b) != string(original) {
+		t.Fatalf("expected b unchanged, got:\n%s", b)
+	}
+
+	if _, err := os.Stat(filepath.Join(td, "b", locsFileName)); err == nil {
+		t.Fatalf("expected no locs table for refused package b")
+	}
+
+	for _, p := range []string{"a", "c"} {
+		if _, err := os.Stat(filepath.Join(td, p, locsFileName)); err != nil {
+			t.Fatalf("expected locs table for %s: %v\n%s", p, err, out)
+		}
+	}
+}
+
+// TestTypeCheck_DryRunReportsRefusal 测试 dry-run 同样执行类型检查, 被拒绝的文件不输出 [PATCH]
+func TestTypeCheck_DryRunReportsRefusal(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	td := writeTree(t, zapModule(t), map[string]string{
+		"a.go": "package app\n\nimport \"go.uber.org/zap\"\n\nfunc Run(extra []any) {\n\tzap.L().Info(\"args\", extra...)\n}\n",
+	})
+
+	*fieldFlg = "fl"
+
+	out := captureOutput(func() {
+		if err := patchFile(filepath.Join(td, "a.go"), token.NewFileSet(), "example.com/app", td); err != nil {
+			t.Errorf("patchFile: %v", err)
+		}
+	})
+
+	if strings.Contains(out, "[PATCH]") || !strings.Contains(out, "warn: type-check") {
+		t.Fatalf("expected refusal without [PATCH], got:\n%s", out)
+	}
+}
+
+// TestExportFiles_OnlyRewrittenPackage 测试类型检查只编译被改写的包的依赖, 不编译 module 中的其他包
+func TestExportFiles_OnlyRewrittenPackage(t *testing.T) {
+	td := writeTree(t, zapModule(t), map[string]string{
+		"svc/a.go":   "package svc\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n\tzap.L().Info(\"run\")\n}\n",
+		"tools/t.go": "package tools\n\nimport \"image/png\"\n\nvar _ = png.Decode\n",
+	})
+
+	exports := exportFiles(filepath.Join(td, "svc"))
+
+	if exports["go.uber.org/zap"] == "" {
+		t.Fatalf("expected export data for zap, got %d packages", len(exports))
+	}
+
+	for _, p := range []string{"example.com/app/tools", "image/png"} {
+		if _, ok := exports[p]; ok {
+			t.Fatalf("expected %s not compiled", p)
+		}
+	}
+}
+
+// TestPackageTypeErrors_BuildTags 测试同包文件按 -tags 与 GOOS 选择
+func TestPackageTypeErrors_BuildTags(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	td := writeTree(t, map[string]string{
+		"prod.go":       "//go:build prod\n\npackage app\n\nconst name = \"prod\"\n",
+		"os_plan9.go":   "package app\n\nconst osName = \"plan9\"\n",
+		"os_windows.go": "package app\n\nconst osName = \"windows\"\n",
+	})
+
+	// os_plan9.go 与 os_windows.go 按文件名只在对应的 GOOS 下参与构建
+	t.Setenv("GOOS", "windows")
+
+	src := []byte("package app\n\nvar _ = name + osName\n")
+	path := filepath.Join(td, "a.go")
+
+	if errs := packageTypeErrors(path, src); errs["undefined: name"] != 1 || len(errs) != 1 {
+		t.Fatalf("expected only name undefined without -tags, got %v", errs)
+	}
+
+	*tagsFlg = "prod"
+
+	if errs := packageTypeErrors(path, src); len(errs) != 0 {
+		t.Fatalf("expected no errors with -tags prod, got %v", errs)
+	}
+}
+
+// TestCheckImporter_ListsPlannedPackagesOnce 测试同一 module 中登记的包目录在首次类型检查时一起执行 go list
+func TestCheckImporter_ListsPlannedPackagesOnce(t *testing.T) {
+	resetGlobals()
+	defer resetGlobals()
+
+	td := writeTree(t, zapModule(t), map[string]string{
+		"svc/a.go":  "package svc\n\nimport \"go.uber.org/zap\"\n\nfunc Run() {\n\tzap.L().Info(\"run\")\n}\n",
+		"jobs/b.go": "package jobs\n\nimport \"image/png\"\n\nvar _ = png.Decode\n",
+	})
+
+	svc, jobs := filepath.Join(td, "svc"), filepath.Join(td, "jobs")
+	planTypeCheck([]string{filepath.Join(svc, "a.go"), filepath.Join(jobs, "b.go")})
+
+	imp := checkImporter(svc)
+
+	c := typeCheckers[typeCheckKey(svc)]
+	if c == nil || !c.listed[jobs] || c.exports["image/png"] == "" || c.exports["go.uber.org/zap"] == "" {
+		t.Fatalf("expected planned packages listed together, got %+v", c)
+	}
+
+	if checkImporter(jobs) != imp {
+		t.Fatalf("expected importer shared within the module")
+	}
+}
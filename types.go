@@ -8,24 +8,27 @@
 
 package main
 
-// zapIdent zap 包的标识符
+import "go/ast"
+
+// zap 调用形式中使用的方法名
 const (
-	zapIdent        = "zap"
-	zapMethodString = "String"
-	zapMethodAny    = "Any"
-	zapMethodUint64 = "Uint64"
+	zapMethodLog   = "Log"   // Logger.Log(lvl, msg, fields...), 同时作为无法静态确定级别时的级别名称
+	zapMethodCheck = "Check" // Logger.Check(lvl, msg) 返回 *zapcore.CheckedEntry
+	zapMethodWrite = "Write" // CheckedEntry.Write(fields...)
 )
 
-// logMethods 列出要注入的 zap 方法名
-var logMethods = map[string]bool{
-	"Debug":  true,
-	"Info":   true,
-	"Warn":   true,
-	"Error":  true,
-	"DPanic": true,
-	"Panic":  true,
-	"Fatal":  true,
-}
+// maxLoggerDepth 追踪 logger 变量初始化表达式时的最大递归深度
+const maxLoggerDepth = 16
+
+// levelNames 列出可在 -level-policy 中配置的级别, 其中 Log 对应通用的 Log(level, ...) 调用
+var levelNames = []string{"Debug", "Info", "Warn", "Error", "DPanic", "Panic", "Fatal", "Log"}
+
+// 级别注入策略
+const (
+	policyInject = "inject" // 注入或更新字段
+	policySkip   = "skip"   // 不注入, 并清理此前由工具注入的字段(zap.String 且值为字符串字面量)
+	policyStrip  = "strip"  // 不注入, 并移除该键的任意字段
+)
 
 // fnRange 源文件中一个函数的字节范围和名字(用于定位调用所在的函数)
 type fnRange struct {
@@ -34,3 +37,74 @@ type fnRange struct {
 	pkg   string // 包名
 	name  string // 函数名
 }
+
+// methodSpec 描述一个日志方法的参数布局
+type methodSpec struct {
+	level     string // 固定的级别名称, 为空时从 levelArg 指定的参数解析
+	levelArg  int    // 级别参数索引, 仅在 level 为空时使用
+	msgIndex  int    // msg 参数索引, 字段参数从其后开始
+	sliceType string // ellipsis 调用包裹注入字段时使用的切片元素类型, 例如 zap.Field、any
+	attrsOnly bool   // 字段参数只能是字段构造调用, 不识别 "key", value 键值对(slog 的 LogAttrs)
+}
+
+// backend 描述一种日志库的调用形态, 由 backendDescriptor 编译得到, 由源文件的导入决定启用哪些 backend
+type backend struct {
+	importPath    string                // 导入路径, 例如 go.uber.org/zap
+	ident         string                // 包标识符, 例如 zap
+	fieldIdent    string                // 字段构造函数所在包的标识符, 通常与 ident 相同
+	rootFuncs     map[string]bool       // 返回 logger 的包级函数, 作为调用链的起点, 例如 zap.L、slog.Default
+	pkgFuncs      bool                  // 日志方法是否可以作为包级函数直接调用, 例如 slog.Info
+	deriveMethods map[string]bool       // 从 logger 派生新 logger 的方法, 用于追踪 logger 变量
+	methods       map[string]methodSpec // 日志方法名到参数布局的映射
+	levelPkgs     map[string]bool       // 级别常量所在包的标识符
+	levelConsts   map[string]string     // 级别常量名到 levelNames 中级别名称的映射
+	fieldFunc     string                // 注入字段使用的构造函数名, 例如 String
+	sortFuncs     map[string]bool       // 参与 -sort 排序的字段构造函数名
+	sliceType     string                // ellipsis 调用包裹注入字段时默认使用的切片元素类型
+	kvPairs       bool                  // 是否识别 "key", value 键值对形式的字段
+	checkWrite    bool                  // 是否支持 Check(lvl, msg).Write(fields...) 形式
+	rootVars      map[string]bool       // 作为调用链起点的包级变量, 例如 zerolog/log 的 log.Logger
+	chainEnds     map[string]bool       // 事件调用链的结束方法, 非空表示字段以链式方法调用表示(zerolog)
+}
+
+// backendConfig backend 描述文件的结构, 内置描述见 backends.json, 自定义描述通过 -backends 指定
+type backendConfig struct {
+	Backends []backendDescriptor `json:"backends"`
+}
+
+// backendDescriptor 以声明方式描述一种日志库的调用形态
+type backendDescriptor struct {
+	Import      string                      `json:"import"`      // 导入路径, 文件导入该路径时启用
+	Roots       []string                    `json:"roots"`       // 调用链起点: pkg.Func(_) 调用(参数忽略)、pkg.Var 包级变量、pkg 表示日志方法可以作为包级函数调用
+	Derive      []string                    `json:"derive"`      // 从 logger 派生新 logger 的方法, 用于追踪 logger 变量
+	Levels      map[string]string           `json:"levels"`      // 级别方法名到级别名称的映射, 字段从 msgIndex+1 开始
+	MsgIndex    int                         `json:"msgIndex"`    // 级别方法的 msg 参数索引
+	Methods     map[string]methodDescriptor `json:"methods"`     // 参数布局与级别方法不同的日志方法, 例如 Log(lvl, msg, fields...)
+	LevelPkgs   []string                    `json:"levelPkgs"`   // 级别常量所在包的标识符
+	LevelConsts map[string]string           `json:"levelConsts"` // 级别常量名到级别名称的映射
+	Field       string                      `json:"field"`       // 字段构造模板, 例如 logx.Str(%q, %q), 链式 backend 为 .Str(%q, %q)
+	SliceType   string                      `json:"sliceType"`   // ellipsis 调用包裹注入字段时使用的切片元素类型, 默认 any
+	SortFuncs   []string                    `json:"sortFuncs"`   // 参与 -sort 排序的字段构造函数名, 默认只有 field 模板中的函数
+	KVPairs     bool                        `json:"kvPairs"`     // 是否识别 "key", value 键值对形式的字段
+	CheckWrite  bool                        `json:"checkWrite"`  // 是否支持 zap 的 Check(lvl, msg).Write(fields...) 形式
+	ChainEnds   []string                    `json:"chainEnds"`   // 事件调用链的结束方法, 非空表示字段以链式方法调用表示
+}
+
+// methodDescriptor 描述单个日志方法的参数布局
+type methodDescriptor struct {
+	Level     string `json:"level"`     // 固定的级别名称, 为空时从 levelArg 指定的参数解析
+	LevelArg  int    `json:"levelArg"`  // 级别参数索引
+	MsgIndex  int    `json:"msgIndex"`  // msg 参数索引
+	SliceType string `json:"sliceType"` // 覆盖 backend 的 sliceType
+	AttrsOnly bool   `json:"attrsOnly"` // 字段参数只能是字段构造调用, 不识别键值对
+}
+
+// fileScope 单个源文件的分析结果, 在遍历该文件的日志调用时共享
+type fileScope struct {
+	file         *ast.File
+	backends     []*backend               // 本文件导入的日志库对应的 backend
+	fns          []fnRange                // 函数范围, 用于定位调用所在的函数
+	loggerFields map[string]*backend      // 在本文件中由 logger 调用链初始化的结构体字段名及其所属 backend
+	locIdents    map[*ast.CallExpr]string // -locs 模式下每个注入目标应引用的位置常量名
+	policy       string                   // 覆盖级别策略的文件级策略, 非空时所有调用按该策略处理(例如 GOGARBLE 范围之外)
+}
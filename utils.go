@@ -13,7 +13,6 @@ import (
 	"go/ast"
 	"go/token"
 	"os"
-	pathpkg "path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -55,45 +54,37 @@ func relPath(path, baseDir string) string {
 	return p
 }
 
-// buildInjectedValue 构造注入的字符串值
+// buildInjectedValue 构造注入的字符串值, -token 模式下为位置的 HMAC 令牌
 func buildInjectedValue(rel string, pos token.Position, funcName, pkgName, modulePath string) string {
 	// 规范路径为 '/' 分隔
 	rel = filepath.ToSlash(rel)
 
-	v := fmt.Sprintf("%s:%d", rel, pos.Line)
-
-	if *funcFlg && funcName != "" {
-		var funcFull string
-
-		if modulePath != "" {
-			dirRel := pathpkg.Dir(rel)
+	importPath := fileImportPath(pos.Filename, rel, modulePath)
 
-			importPath := modulePath
-
-			if dirRel != "." && dirRel != "" {
-				importPath = pathpkg.Join(modulePath, dirRel)
-			}
+	if *tokenFlg {
+		return siteToken(rel, pos.Line, qualifiedFuncName(importPath, funcName, pkgName))
+	}
 
-			funcFull = fmt.Sprintf("%s.%s", importPath, funcName)
-		} else {
-			funcFull = fmt.Sprintf("%s.%s", pkgName, funcName)
-		}
+	v := fmt.Sprintf("%s:%d", rel, pos.Line)
 
-		v = fmt.Sprintf("%s | %s", v, funcFull)
+	if *funcFlg && funcName != "" {
+		v = fmt.Sprintf("%s | %s", v, qualifiedFuncName(importPath, funcName, pkgName))
 	}
 
 	return v
 }
 
-// hasZapImport 判断 ast.File 是否导入了 go.uber.org/zap
-func hasZapImport(file *ast.File) bool {
-	for _, imp := range file.Imports {
-		if strings.Trim(imp.Path.Value, "\"") == "go.uber.org/zap" {
-			return true
-		}
+// qualifiedFuncName 返回函数的完整名称: 有导入路径(见 fileImportPath)时为 导入路径.函数名, 否则为 包名.函数名; funcName 为空时返回空串
+func qualifiedFuncName(importPath, funcName, pkgName string) string {
+	if funcName == "" {
+		return ""
 	}
 
-	return false
+	if importPath == "" {
+		return fmt.Sprintf("%s.%s", pkgName, funcName)
+	}
+
+	return fmt.Sprintf("%s.%s", importPath, funcName)
 }
 
 // collectFuncLitRanges 提取文件中所有匿名函数的范围信息并返回
@@ -210,20 +201,6 @@ func findBestParent(fns []fnRange, lr fnRange) int {
 	return bestIdx
 }
 
-// getZapFieldKey 从 zap 字段调用 (例如 zap.String("key", "val")) 中提取 key
-func getZapFieldKey(call *ast.CallExpr) string {
-	if len(call.Args) == 0 {
-		return ""
-	}
-
-	bl, ok := call.Args[0].(*ast.BasicLit)
-	if !ok {
-		return ""
-	}
-
-	return unquoteLiteral(bl.Value)
-}
-
 // unquoteLiteral 将字符串字面量值解引号, 如果 Unquote 失败则去除两侧双引号
 func unquoteLiteral(raw string) string {
 	if s, err := strconv.Unquote(raw); err == nil {
@@ -233,38 +210,6 @@ func unquoteLiteral(raw string) string {
 	return strings.Trim(raw, "\"")
 }
 
-// extractZapFieldKV 从单个 zap 字段调用表达式中提取 "key=value" 字符串。
-// 如果不是有效的 zap 字段调用, 返回空字符串
-func extractZapFieldKV(a ast.Expr) string {
-	call, ok := a.(*ast.CallExpr)
-	if !ok {
-		return ""
-	}
-
-	funSel, ok := call.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return ""
-	}
-
-	id, ok := funSel.X.(*ast.Ident)
-	if !ok || id.Name != zapIdent {
-		return ""
-	}
-
-	if len(call.Args) == 0 {
-		return ""
-	}
-
-	key := parseLitKey(call.Args[0])
-	if key == "" {
-		return ""
-	}
-
-	val := parseLitVal(call.Args)
-
-	return fmt.Sprintf("%s=%s", key, val)
-}
-
 // parseLitKey 从 AST 表达式中解析字符串字面量的 key 值
 func parseLitKey(expr ast.Expr) string {
 	bl, ok := expr.(*ast.BasicLit)
@@ -275,56 +220,45 @@ func parseLitKey(expr ast.Expr) string {
 	return unquoteLiteral(bl.Value)
 }
 
-// parseLitVal 从 zap 字段参数列表中解析 value 值
-func parseLitVal(args []ast.Expr) string {
-	if len(args) <= 1 {
+// parseLitVal 从字段值表达式中解析 value 值
+func parseLitVal(v ast.Expr) string {
+	if v == nil {
 		return "<missing>"
 	}
 
-	bl, ok := args[1].(*ast.BasicLit)
+	s, ok := fieldValueString(v)
 	if !ok {
 		return "<non-literal>"
 	}
 
-	return unquoteLiteral(bl.Value)
+	return s
 }
 
-// collectExistingFields 遍历 ce.Args[1:], 收集所有 zap 字段调用的 "key=value" 字符串列表
-func collectExistingFields(ce *ast.CallExpr) []string {
-	var fields []string
-
-	for _, a := range ce.Args[1:] {
-		if kv := extractZapFieldKV(a); kv != "" {
-			fields = append(fields, kv)
-		}
+// fieldValueString 解析注入字段的值: 字符串字面量返回解引号后的值, 位置常量引用返回常量名
+func fieldValueString(v ast.Expr) (string, bool) {
+	switch e := v.(type) {
+	case *ast.BasicLit:
+		return unquoteLiteral(e.Value), true
+	case *ast.Ident:
+		return e.Name, isLocIdent(e)
 	}
 
-	return fields
+	return "", false
 }
 
-// isZapFieldCall 判断 expr 是否为 zap.String/Any/Uint64 的调用, 并返回对应的 CallExpr
-func isZapFieldCall(expr ast.Expr) (*ast.CallExpr, bool) {
-	call, ok := expr.(*ast.CallExpr)
-	if !ok {
-		return nil, false
-	}
-
-	funSel, ok := call.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return nil, false
-	}
+// collectExistingFields 遍历 lc 的字段参数, 收集所有带字面量 key 的字段的 "key=value" 字符串列表
+func collectExistingFields(lc logCall) []string {
+	var fields []string
 
-	id, ok := funSel.X.(*ast.Ident)
-	if !ok || id.Name != zapIdent {
-		return nil, false
-	}
+	for _, f := range parseFields(lc) {
+		if f.key == "" || (f.call == nil && !f.isPair()) {
+			continue
+		}
 
-	name := funSel.Sel.Name
-	if name != zapMethodString && name != zapMethodAny && name != zapMethodUint64 {
-		return nil, false
+		fields = append(fields, fmt.Sprintf("%s=%s", f.key, parseLitVal(f.value)))
 	}
 
-	return call, true
+	return fields
 }
 
 // normalizeBaseDir 将 dir 参数标准化为目录路径(如果传入文件则返回其所在目录)
@@ -333,13 +267,14 @@ func normalizeBaseDir(dir string) (string, error) {
 	if fi, err := os.Stat(baseDir); err == nil {
 		if !fi.IsDir() {
 			// 如果传入的是文件路径, 使用工作目录作为仓库根路径,
-			// 以便生成相对于仓库根的文件路径 (例如 "cron/task_coupon_status.go")
+			// 以便生成相对于仓库根的文件路径 (例如 "cron/task_coupon_status.go");
+			// -rel-to 不为 path 时使用文件所属 module(工作区、仓库)的根目录
 			wd, err := os.Getwd()
 			if err != nil {
 				return "", fmt.Errorf("os.Getwd failed: %w", err)
 			}
 
-			return wd, nil
+			return relRoot(baseDir, wd), nil
 		}
 	}
 
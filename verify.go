@@ -13,7 +13,6 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"os"
 	"strings"
 
 	"github.com/jiaopengzi/go-utils"
@@ -21,68 +20,110 @@ import (
 
 // verifyResult 校验单次调用的统计结果
 type verifyResult struct {
-	total    int
-	missing  int
-	mismatch int
-	issues   []string
+	total      int
+	missing    int
+	mismatch   int
+	unexpected int
+	locs       int // -locs 模式下位置常量表的问题数
+	smap       int // -token 模式下映射文件的问题数
+	failed     int // 无法读取或解析的文件数
+	collision  int // 与注入字段同名但不是由工具注入的字段数
+	issues     []string
+	values     []string // 目标调用期望的运行时注入值(-locs 模式下为位置常量的值), 供 audit-binary 对照
+}
+
+// add 将 o 的统计结果累加到 vr
+func (vr *verifyResult) add(o verifyResult) {
+	vr.total += o.total
+	vr.missing += o.missing
+	vr.mismatch += o.mismatch
+	vr.unexpected += o.unexpected
+	vr.locs += o.locs
+	vr.smap += o.smap
+	vr.failed += o.failed
+	vr.collision += o.collision
+	vr.issues = append(vr.issues, o.issues...)
+	vr.values = append(vr.values, o.values...)
 }
 
 // verifyFile 在不修改文件的情况下校验每个 zap 日志调用的注入字段是否存在且值是否正确
-func verifyFile(path string, fSet *token.FileSet, modulePath string, baseDir string) (int, int, int, []string, error) {
+func verifyFile(path string, fSet *token.FileSet, modulePath string, baseDir string) (verifyResult, error) {
 	// 读取文件内容
 	src, err := utils.ReadFile(path)
 	if err != nil {
-		return 0, 0, 0, nil, err
+		return verifyResult{}, &fileError{op: opRead, path: path, err: err}
 	}
 
-	// 解析文件为 AST
+	// 解析文件为 AST, 无法解析的文件计为失败而不是没有日志调用
 	file, err := parser.ParseFile(fSet, path, src, parser.ParseComments)
 	if err != nil {
-		// 解析失败时至少记录警告, 避免静默跳过
-		fmt.Fprintf(os.Stderr, "warn: parse %s failed: %v\n", path, err)
-		return 0, 0, 0, nil, nil
+		return verifyResult{}, &fileError{op: opParse, path: path, err: err}
+	}
+
+	// 收集文件导入的日志库、每个函数的范围信息(用于定位调用处所属的函数)以及由调用链初始化的 logger 变量
+	sc := newFileScope(file, fSet)
+
+	// 如果没有导入任何支持的日志库, 则无需继续处理, 提前返回
+	if len(sc.backends) == 0 {
+		return verifyResult{}, nil
 	}
 
-	// 判断是否包含 zap 导入
-	// 如果没有导入 go.uber.org/zap, 则无需继续处理, 提前返回
-	if !hasZapImport(file) {
-		return 0, 0, 0, nil, nil
+	// -garble-scope 模式: GOGARBLE 范围之外的文件不校验, 或按 -garble-strip 校验字段已移除
+	if !applyGarbleScope(sc, path, modulePath, baseDir) {
+		return verifyResult{}, nil
 	}
 
-	// 收集文件中每个函数的范围信息, 用于后续定位调用处所属的函数(以便构造完整函数路径)
-	fns := collectFuncRanges(file, fSet)
+	// -locs 模式: 为每个注入目标确定期望引用的位置常量名
+	if locTables != nil {
+		sc.locIdents = assignLocIdents(sc, fSet, path, baseDir)
+	}
 
 	// 遍历 AST 节点, 收集校验结果
-	vr := verifyFileInspect(file, fSet, fns, modulePath, baseDir)
-
-	return vr.total, vr.missing, vr.mismatch, vr.issues, nil
+	return verifyFileInspect(sc, fSet, modulePath, baseDir), nil
 }
 
 // verifyFileInspect 遍历 AST 节点, 定位所有函数调用并委托给 verifyCallExpr 完成单次调用的校验,
 // 返回汇总的校验结果
-func verifyFileInspect(file *ast.File, fSet *token.FileSet, fns []fnRange, modulePath, baseDir string) verifyResult {
+func verifyFileInspect(sc *fileScope, fSet *token.FileSet, modulePath, baseDir string) verifyResult {
 	var vr verifyResult
 
-	ast.Inspect(file, func(n ast.Node) bool {
+	ast.Inspect(sc.file, func(n ast.Node) bool {
 		ce, ok := n.(*ast.CallExpr)
 		if !ok {
 			return true
 		}
 
-		sel, ok := ce.Fun.(*ast.SelectorExpr)
+		lc, ok := matchLogCall(ce, sc)
 		if !ok {
 			return true
 		}
 
 		// 对单个调用进行校验
-		shouldCount, issue, isMissing, isMismatch := verifyCallExpr(ce, sel, fSet, file, fns, modulePath, baseDir)
+		shouldCount, issue, isMissing, isMismatch := verifyCallExpr(lc, fSet, sc, modulePath, baseDir)
 		if !shouldCount {
+			// 非注入目标: 检查 skip/strip 级别的调用是否仍残留注入字段
+			if issue := verifyUnexpectedField(lc, fSet, sc, baseDir); issue != "" {
+				vr.issues = append(vr.issues, issue)
+				vr.unexpected++
+			}
+
 			return true
 		}
 
-		// 统计目标日志调用总数
+		// 统计目标日志调用总数, 并记录期望的运行时注入值
 		vr.total++
 
+		pos, rel, funcName, pkgName := siteInfo(lc, fSet, sc, baseDir)
+		vr.values = append(vr.values, buildInjectedValue(rel, pos, funcName, pkgName, modulePath))
+
+		// 同名字段不是由工具注入时报告为冲突, 而不是 mismatch
+		if field := collidingField(lc); field != "" {
+			vr.issues = append(vr.issues, collisionIssue(lc, rel, pos.Line, field))
+			vr.collision++
+
+			return true
+		}
+
 		// 如果 verifyCallExpr 返回了 issue, 则记录并根据问题类型更新相应计数器
 		if issue != "" {
 			vr.issues = append(vr.issues, issue)
@@ -104,50 +145,74 @@ func verifyFileInspect(file *ast.File, fSet *token.FileSet, fns []fnRange, modul
 
 // verifyAndHandleSingleFile 对单个文件执行 verify 并处理结果
 func verifyAndHandleSingleFile(path string, fSet *token.FileSet, modulePath, baseDir string) error {
-	total, _, _, _, err := reportVerifyForPath(path, fSet, modulePath, baseDir)
-	if err != nil {
-		return err
+	if _, err := reportVerifyForPath(path, fSet, modulePath, baseDir); err != nil {
+		return handleFileError(err)
 	}
 
-	// 当前文件无注入点或者无问题: nothing more to do
-	if total == 0 {
-		return nil
-	}
+	recordGenerated(path, false, "", modulePath, baseDir)
+	reportLocTables(modulePath, baseDir)
+	reportSourceMap([]string{smapScope(path, baseDir)}, baseDir)
 
 	return nil
 }
 
-// reportVerifyForPath 运行 verifyFile 并打印问题（如果有），返回统计数据
-func reportVerifyForPath(path string, fSet *token.FileSet, modulePath, baseDir string) (int, int, int, []string, error) {
-	total, missing, mismatch, issues, err := verifyFile(path, fSet, modulePath, baseDir)
-	if err != nil {
-		return 0, 0, 0, nil, err
+// reportSourceMap 校验 -token 模式的映射文件并打印问题, 返回问题数
+func reportSourceMap(scopes []string, baseDir string) int {
+	issues := verifySourceMap(scopes, baseDir)
+	if len(issues) == 0 {
+		return 0
 	}
 
-	if total == 0 {
-		return total, missing, mismatch, issues, nil
+	fmt.Printf("[VERIFY] %s: smap=%d\n", relPath(smapPath(baseDir), baseDir), len(issues))
+
+	for _, it := range issues {
+		fmt.Println(it)
 	}
 
-	if len(issues) > 0 {
+	return len(issues)
+}
+
+// reportVerifyForPath 运行 verifyFile 并打印问题（如果有），返回统计数据
+func reportVerifyForPath(path string, fSet *token.FileSet, modulePath, baseDir string) (verifyResult, error) {
+	vr, err := verifyFile(path, fSet, modulePath, baseDir)
+	if err != nil {
+		return verifyResult{}, err
+	}
+
+	if len(vr.issues) > 0 {
 		rel := relPath(path, baseDir)
-		fmt.Printf("[VERIFY] %s: total=%d missing=%d mismatch=%d\n", rel, total, missing, mismatch)
+		fmt.Printf("[VERIFY] %s: total=%d missing=%d mismatch=%d unexpected=%d\n", rel, vr.total, vr.missing, vr.mismatch, vr.unexpected)
 
-		for _, it := range issues {
+		for _, it := range vr.issues {
 			fmt.Println(it)
 		}
 	}
 
-	return total, missing, mismatch, issues, nil
+	return vr, nil
 }
 
 // printVerifySummary 打印汇总报告, 包括存在问题的文件列表。
-//   - totalAll, 目标日志调用总数。
-//   - missingAll, 缺失注入的调用数。
-//   - mismatchAll, 注入值不匹配的调用数。
+//   - vr, 所有文件累加后的校验结果。
 //   - issueFiles, 存在问题的文件列表。
-func printVerifySummary(totalAll, missingAll, mismatchAll int, issueFiles []string) {
+func printVerifySummary(vr verifyResult, issueFiles []string) {
 	fmt.Printf("\n===== VERIFY SUMMARY =====\n")
-	fmt.Printf("total calls: %d\nmissing: %d\nmismatch: %d\n", totalAll, missingAll, mismatchAll)
+	fmt.Printf("total calls: %d\nmissing: %d\nmismatch: %d\nunexpected: %d\n", vr.total, vr.missing, vr.mismatch, vr.unexpected)
+
+	if vr.collision > 0 {
+		fmt.Printf("collision: %d\n", vr.collision)
+	}
+
+	if vr.failed > 0 {
+		fmt.Printf("failed: %d\n", vr.failed)
+	}
+
+	if *locsFlg {
+		fmt.Printf("locs: %d\n", vr.locs)
+	}
+
+	if *tokenFlg {
+		fmt.Printf("smap: %d\n", vr.smap)
+	}
 
 	if len(issueFiles) > 0 {
 		fmt.Printf("\nfiles with issues (%d):\n", len(issueFiles))
@@ -157,64 +222,83 @@ func printVerifySummary(totalAll, missingAll, mismatchAll int, issueFiles []stri
 		}
 	}
 
-	if missingAll == 0 && mismatchAll == 0 {
+	if vr.missing == 0 && vr.mismatch == 0 && vr.unexpected == 0 && vr.locs == 0 && vr.smap == 0 && vr.failed == 0 && vr.collision == 0 {
 		fmt.Println("\nAll injections look correct.")
 	}
 }
 
+// verifyUnexpectedField 检查策略为 skip/strip 的调用是否仍带有 -field 指定的字段,
+// 返回问题描述, 无问题返回空串
+func verifyUnexpectedField(lc logCall, fSet *token.FileSet, sc *fileScope, baseDir string) string {
+	policy := sc.policyFor(lc.level)
+	if policy == policyInject {
+		return ""
+	}
+
+	// 能被移除即说明字段残留
+	if !hasRemovableField(lc, *fieldFlg, policy == policySkip) {
+		return ""
+	}
+
+	pos := fSet.Position(lc.site)
+	rel := relPath(pos.Filename, baseDir)
+
+	reason := "level policy " + policy
+	if sc.policy != "" {
+		reason = "outside GOGARBLE scope"
+	}
+
+	return fmt.Sprintf("%s:%d: %s.%s unexpected field '%s' (%s)", rel, pos.Line, lc.b.ident, lc.method, *fieldFlg, reason)
+}
+
 // verifyCallExpr 验证单次 zap 日志调用是否包含正确的注入字段
 // 返回: shouldCount(是否为目标日志调用需要计入统计), issue(若不为空则为问题描述), isMissing, isMismatch
 func verifyCallExpr(
-	ce *ast.CallExpr,
-	sel *ast.SelectorExpr,
+	lc logCall,
 	fSet *token.FileSet,
-	file *ast.File,
-	fns []fnRange,
+	sc *fileScope,
 	modulePath, baseDir string,
 ) (bool, string, bool, bool) {
-	isTarget, pos, rel, _, _, expected, foundIndex := analyzeCallExpr(ce, sel, fSet, file, fns, modulePath, baseDir)
+	isTarget, pos, rel, _, _, expected, foundIndex := analyzeCallExpr(lc, fSet, sc, modulePath, baseDir)
 	if !isTarget {
 		return false, "", false, false
 	}
 
-	method := sel.Sel.Name
-
 	// ellipsis 路径: 检查 append 包裹内部的注入字段
-	if ce.Ellipsis.IsValid() {
-		return verifyEllipsisCall(ce, rel, pos, method, expected)
+	if lc.isEllipsis() {
+		return verifyEllipsisCall(lc, rel, pos, expected)
 	}
 
 	// 非 ellipsis 路径
-	return verifyNonEllipsisCall(ce, rel, pos, method, expected, foundIndex, baseDir)
+	return verifyNonEllipsisCall(lc, rel, pos, expected, foundIndex, baseDir)
 }
 
 // verifyEllipsisCall 校验 ellipsis 展开调用中的注入字段
 // 返回: shouldCount, issue, isMissing, isMismatch
-func verifyEllipsisCall(ce *ast.CallExpr, rel string, pos token.Position, method, expected string) (bool, string, bool, bool) {
+func verifyEllipsisCall(lc logCall, rel string, pos token.Position, expected string) (bool, string, bool, bool) {
+	ce, method := lc.ce, lc.b.ident+"."+lc.method
 	lastIdx := len(ce.Args) - 1
 	expandedArg := ce.Args[lastIdx]
 
-	_, zapCall, _ := findEllipsisFieldCall(expandedArg, *fieldFlg)
+	_, fieldCall, _ := findEllipsisFieldCall(lc.b, expandedArg, *fieldFlg)
 
-	if zapCall == nil {
+	if fieldCall == nil {
 		// 缺失: 展开参数未被 append([]zap.Field{zap.String("fl", "...")}, x...) 包裹
-		return true, fmt.Sprintf("%s:%d: zap.%s missing field '%s' (ellipsis call), expected='%s'", rel, pos.Line, method, *fieldFlg, expected), true, false
+		return true, fmt.Sprintf("%s:%d: %s missing field '%s' (ellipsis call), expected='%s'", rel, pos.Line, method, *fieldFlg, expected), true, false
 	}
 
 	// 检查值是否匹配
-	if len(zapCall.Args) < 2 {
-		return true, fmt.Sprintf("%s:%d: zap.%s field '%s' has insufficient args in append wrapper", rel, pos.Line, method, *fieldFlg), false, false
+	if len(fieldCall.Args) < 2 {
+		return true, fmt.Sprintf("%s:%d: %s field '%s' has insufficient args in append wrapper", rel, pos.Line, method, *fieldFlg), false, false
 	}
 
-	bl, ok := zapCall.Args[1].(*ast.BasicLit)
+	actual, ok := fieldValueString(fieldCall.Args[1])
 	if !ok {
-		return true, fmt.Sprintf("%s:%d: zap.%s field '%s' value is not a string literal", rel, pos.Line, method, *fieldFlg), false, false
+		return true, fmt.Sprintf("%s:%d: %s field '%s' value is not a string literal", rel, pos.Line, method, *fieldFlg), false, false
 	}
 
-	actual := unquoteLiteral(bl.Value)
-
 	if actual != expected {
-		return true, fmt.Sprintf("%s:%d: zap.%s field '%s' mismatch actual='%s' expected='%s'", rel, pos.Line, method, *fieldFlg, actual, expected), false, true
+		return true, fmt.Sprintf("%s:%d: %s field '%s' mismatch actual='%s' expected='%s'", rel, pos.Line, method, *fieldFlg, actual, expected), false, true
 	}
 
 	return true, "", false, false
@@ -222,21 +306,23 @@ func verifyEllipsisCall(ce *ast.CallExpr, rel string, pos token.Position, method
 
 // verifyNonEllipsisCall 校验非 ellipsis 调用中的注入字段
 // 返回: shouldCount, issue, isMissing, isMismatch
-func verifyNonEllipsisCall(ce *ast.CallExpr, rel string, pos token.Position, method, expected string, foundIndex int, baseDir string) (bool, string, bool, bool) {
+func verifyNonEllipsisCall(lc logCall, rel string, pos token.Position, expected string, foundIndex int, baseDir string) (bool, string, bool, bool) {
+	method := lc.b.ident + "." + lc.method
+
 	// 收集现有字段列表用于更友好的错误提示
-	existingFields := collectExistingFields(ce)
+	existingFields := collectExistingFields(lc)
 
 	if foundIndex < 0 {
 		existStr := strings.Join(existingFields, ", ")
-		return true, fmt.Sprintf("%s:%d: zap.%s missing field '%s', expected='%s', existing fields: [%s]", rel, pos.Line, method, *fieldFlg, expected, existStr), true, false
+		return true, fmt.Sprintf("%s:%d: %s missing field '%s', expected='%s', existing fields: [%s]", rel, pos.Line, method, *fieldFlg, expected, existStr), true, false
 	}
 
-	issue, isMismatch, actual, existing := verifyExistingField(ce, foundIndex, expected, pos, baseDir)
+	issue, isMismatch, actual, existing := verifyExistingField(lc, foundIndex, expected, pos, baseDir)
 	if issue != "" {
 		existStr := strings.Join(existing, ", ")
 
 		if isMismatch {
-			return true, fmt.Sprintf("%s:%d: zap.%s field '%s' mismatch actual='%s' expected='%s', existing fields: [%s]", rel, pos.Line, method, *fieldFlg, actual, expected, existStr), false, true
+			return true, fmt.Sprintf("%s:%d: %s field '%s' mismatch actual='%s' expected='%s', existing fields: [%s]", rel, pos.Line, method, *fieldFlg, actual, expected, existStr), false, true
 		}
 
 		// 其他类型的问题(非值不匹配)
@@ -248,18 +334,18 @@ func verifyNonEllipsisCall(ce *ast.CallExpr, rel string, pos token.Position, met
 			detail = parts[1]
 		}
 
-		return true, fmt.Sprintf("%s:%d: zap.%s %s, existing fields: [%s]", rel, pos.Line, method, detail, existStr), false, false
+		return true, fmt.Sprintf("%s:%d: %s %s, existing fields: [%s]", rel, pos.Line, method, detail, existStr), false, false
 	}
 
 	return true, "", false, false
 }
 
-// verifyExistingField 检查已存在的字段表达式是否为 zap.String(key, value) 形式, 且 key 为 fieldFlg,
+// verifyExistingField 检查已存在的字段表达式是否为 zap.String(key, value) 或 "key", value 键值对形式, 且 key 为 fieldFlg,
 // value 为期望的字符串值 expected。
 //
 // 参数:
-//   - ce: 包含该字段参数的调用表达式(外层日志调用的参数列表)
-//   - foundIndex: 在 ce.Args 中该字段参数的索引
+//   - lc: 包含该字段参数的日志调用
+//   - foundIndex: 在 lc.fields() 中该字段的索引
 //   - expected: 期望的字符串值(由 buildInjectedValue 构造)
 //   - pos: 调用位置(用于构造文件:行号的错误信息)
 //   - baseDir: 仓库根目录
@@ -269,57 +355,64 @@ func verifyNonEllipsisCall(ce *ast.CallExpr, rel string, pos token.Position, met
 //   - isMismatch: 如果问题类型为值不匹配(actual != expected)则为 true, 其他错误类型返回 false
 //   - actual: 实际的字段值
 //   - existing: 现有字段列表
-func verifyExistingField(ce *ast.CallExpr, foundIndex int, expected string, pos token.Position, baseDir string) (string, bool, string, []string) {
+func verifyExistingField(lc logCall, foundIndex int, expected string, pos token.Position, baseDir string) (string, bool, string, []string) {
 	// 收集现有字段列表
-	existing := collectExistingFields(ce)
+	existing := collectExistingFields(lc)
 
 	// 校验字段表达式是否为 zap.String 且值正确
-	issue, isMismatch, actual := validateZapStringField(ce, foundIndex, expected, pos, baseDir)
+	issue, isMismatch, actual := validateStringField(lc, foundIndex, expected, pos, baseDir)
 
 	return issue, isMismatch, actual, existing
 }
 
-// validateZapStringField 校验 ce.Args[foundIndex] 是否为 zap.String(key, value) 调用,
+// validateStringField 校验 lc.fields()[foundIndex] 是否为 zap.String(key, value) 调用或 "key", value 键值对,
 // 并检查 value 是否与 expected 一致
 //
 // 返回:
 //   - issue: 若非空表示存在问题
 //   - isMismatch: 问题类型是否为值不匹配
 //   - actual: 实际的字段值
-func validateZapStringField(ce *ast.CallExpr, foundIndex int, expected string, pos token.Position, baseDir string) (string, bool, string) {
+func validateStringField(lc logCall, foundIndex int, expected string, pos token.Position, baseDir string) (string, bool, string) {
 	rel := relPath(pos.Filename, baseDir)
+	exprs := lc.fields()
 
-	// 1) 确认该参数是一个调用表达式 (例如: zap.String(...))
-	call, ok := ce.Args[foundIndex].(*ast.CallExpr)
-	if !ok {
-		return fmt.Sprintf("%s:%d: field arg not a call expression", rel, pos.Line), true, ""
-	}
+	var value ast.Expr
 
-	// 2) 确认调用的函数是一个 SelectorExpr (例如 zap.String)
-	funSel, ok := call.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return fmt.Sprintf("%s:%d: unexpected expression for field arg", rel, pos.Line), true, ""
-	}
+	if lc.kvPairs && isStringLit(exprs[foundIndex]) {
+		// 键值对形式: 值为紧随其后的参数
+		value = exprs[foundIndex+1]
+	} else {
+		// 1) 确认该参数是一个调用表达式 (例如: zap.String(...))
+		call, ok := exprs[foundIndex].(*ast.CallExpr)
+		if !ok {
+			return fmt.Sprintf("%s:%d: field arg not a call expression", rel, pos.Line), true, ""
+		}
 
-	// 3) 确认接收者为 zap 且方法名为 String
-	if !isZapStringSelector(funSel) {
-		return fmt.Sprintf("%s:%d: expected zap.String call for field", rel, pos.Line), true, ""
-	}
+		// 2) 确认调用的函数是一个 SelectorExpr (例如 zap.String)
+		if _, ok := call.Fun.(*ast.SelectorExpr); !ok {
+			return fmt.Sprintf("%s:%d: unexpected expression for field arg", rel, pos.Line), true, ""
+		}
 
-	// 4) zap.String 至少应有两个参数 (key, value)
-	if len(call.Args) <= 1 {
-		return fmt.Sprintf("%s:%d: zap.String has insufficient args", rel, pos.Line), true, ""
+		// 3) 确认接收者为 backend 的包标识符且方法名为 String
+		fieldFunc := lc.b.fieldFuncName()
+		if !lc.b.isFieldFunc(call) {
+			return fmt.Sprintf("%s:%d: expected %s call for field", rel, pos.Line, fieldFunc), true, ""
+		}
+
+		// 4) zap.String 至少应有两个参数 (key, value)
+		if len(call.Args) <= 1 {
+			return fmt.Sprintf("%s:%d: %s has insufficient args", rel, pos.Line, fieldFunc), true, ""
+		}
+
+		value = call.Args[1]
 	}
 
-	// 5) 第二个参数应为字符串字面量
-	bl, ok := call.Args[1].(*ast.BasicLit)
+	// 5) 值应为字符串字面量(-locs 模式下为位置常量), 解析后与期望值比较
+	actual, ok := fieldValueString(value)
 	if !ok {
 		return fmt.Sprintf("%s:%d: mismatched type for field, expected basic literal", rel, pos.Line), true, ""
 	}
 
-	// 6) 解析字面量为字符串并与期望值比较
-	actual := unquoteLiteral(bl.Value)
-
 	if actual != expected {
 		return fmt.Sprintf("%s:%d: mismatch actual='%s' expected='%s'", rel, pos.Line, actual, expected), true, actual
 	}
@@ -327,10 +420,3 @@ func validateZapStringField(ce *ast.CallExpr, foundIndex int, expected string, p
 	// 校验通过
 	return "", false, actual
 }
-
-// isZapStringSelector 判断 SelectorExpr 是否为 zap.String
-func isZapStringSelector(sel *ast.SelectorExpr) bool {
-	id, ok := sel.X.(*ast.Ident)
-
-	return ok && id.Name == zapIdent && sel.Sel.Name == zapMethodString
-}
@@ -114,3 +114,34 @@ func Foo() { zap.L().Info("hello", zap.String("file:line","verify_with_func.go:5
 		t.Fatalf("expected verify summary with zero issues for with-func, got: %s", out)
 	}
 }
+
+func TestMain_VerifyMode_LevelPolicyUnexpectedField(t *testing.T) {
+	resetGlobals()
+
+	td := t.TempDir()
+	writeFile(t, td, "verify_policy.go", `package sample
+
+import "go.uber.org/zap"
+
+func Foo() {
+	zap.L().Debug("debug", zap.String("file:line", "verify_policy.go:6"))
+	zap.L().Warn("warn", zap.String("file:line", "verify_policy.go:7"))
+}
+`)
+
+	*pathFlag = td
+	*verifyFlg = true
+	*policyFlg = "Debug=skip"
+	os.Args = []string{"cmd"}
+
+	out := captureOutput(func() {
+		main()
+	})
+
+	if !strings.Contains(out, "unexpected field 'file:line'") {
+		t.Fatalf("expected unexpected field reported for skipped level, got: %s", out)
+	}
+	if !strings.Contains(out, "total calls: 1") || !strings.Contains(out, "unexpected: 1") {
+		t.Fatalf("expected skipped call excluded from total and counted as unexpected, got: %s", out)
+	}
+}
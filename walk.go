@@ -9,6 +9,7 @@
 package main
 
 import (
+	"fmt"
 	"go/token"
 	"io/fs"
 	"os"
@@ -29,12 +30,11 @@ func runSingleFileMode(path string, fSet *token.FileSet, modulePath, baseDir str
 	}
 
 	// 处理单个文件的 AST 注入逻辑
-	modified, out, modifiedLines, err := processFile(path, fSet, modulePath, baseDir)
-	if err != nil {
+	if err := patchFile(path, fSet, modulePath, baseDir); err != nil {
 		return err
 	}
 
-	return applyPatchIfModified(path, modified, out, modifiedLines, baseDir)
+	return writeGenerated([]string{smapScope(path, baseDir)}, modulePath, baseDir)
 }
 
 // runDirectoryMode 处理目录遍历模式
@@ -48,9 +48,11 @@ func runDirectoryMode(target string, fSet *token.FileSet, modulePath, baseDir st
 
 // runPatchWalk 遍历目录并对每个文件执行 AST 注入/写回(非 verify 模式)
 func runPatchWalk(target string, fSet *token.FileSet, modulePath, baseDir string) error {
-	return filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+	var files []string
+
+	err := filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return err
+			return handleFileError(&fileError{op: opRead, path: path, err: err})
 		}
 
 		if info.IsDir() {
@@ -61,28 +63,75 @@ func runPatchWalk(target string, fSet *token.FileSet, modulePath, baseDir string
 			return nil
 		}
 
-		if shouldSkipFile(path) {
-			return nil
+		if !shouldSkipFile(path) {
+			files = append(files, path)
 		}
 
-		modified, out, modifiedLines, err := processFile(path, fSet, modulePath, baseDir)
-		if err != nil {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	planTypeCheck(files)
+
+	for _, path := range files {
+		if err := patchFile(path, fSet, modulePath, baseDir); err != nil {
 			return err
 		}
+	}
 
-		return applyPatchIfModified(path, modified, out, modifiedLines, baseDir)
-	})
+	// 写出 -locs 位置常量表与 -token 映射文件
+	return writeGenerated([]string{smapScope(target, baseDir)}, modulePath, baseDir)
+}
+
+// patchFile 对单个文件执行 AST 注入并写回或预览, 失败交由 handleFileError 处理。
+// 写回或预览成功后才登记生成内容中的位置; 被拒绝或写回失败的文件保持原样, 按磁盘上的内容登记
+func patchFile(path string, fSet *token.FileSet, modulePath, baseDir string) error {
+	modified, out, modifiedLines, err := processFile(path, fSet, modulePath, baseDir)
+	if err != nil {
+		return handleFileError(err)
+	}
+
+	if err := applyPatchIfModified(path, modified, out, modifiedLines, baseDir); err != nil {
+		recordGenerated(path, false, "", modulePath, baseDir)
+		return handleFileError(err)
+	}
+
+	recordGenerated(path, modified, out, modulePath, baseDir)
+
+	return nil
+}
+
+// recordGenerated 登记文件最终内容中的位置常量引用与令牌, 供生成或校验 zz_smap_locs.go 与 smap.json
+func recordGenerated(path string, modified bool, out string, modulePath, baseDir string) {
+	recordLocs(path, modified, out, modulePath, baseDir)
+	recordSites(path, modified, out, modulePath, baseDir)
+}
+
+// writeGenerated 写出 -locs 位置常量表与 -token 映射文件, scopes 为本次运行覆盖的范围(见 smapScope)
+func writeGenerated(scopes []string, modulePath, baseDir string) error {
+	if err := writeLocTables(modulePath, baseDir); err != nil {
+		return err
+	}
+
+	return writeSourceMap(scopes, baseDir)
 }
 
 // runVerifyWalk 遍历目录并在 verify 模式下收集并打印汇总
 func runVerifyWalk(target string, fSet *token.FileSet, modulePath, baseDir string) error {
-	var totalAll, missingAll, mismatchAll int
+	var sum verifyResult
 
 	// 收集有问题的文件路径
 	var issueFiles []string
 
 	err := filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
+			if err = handleFileError(&fileError{op: opRead, path: path, err: err}); err == nil {
+				sum.failed++
+				issueFiles = append(issueFiles, relPath(path, baseDir))
+			}
+
 			return err
 		}
 
@@ -90,10 +139,8 @@ func runVerifyWalk(target string, fSet *token.FileSet, modulePath, baseDir strin
 			return handleVerifyDir(path)
 		}
 
-		t, m, mm, files := verifyWalkFile(path, fSet, modulePath, baseDir)
-		totalAll += t
-		missingAll += m
-		mismatchAll += mm
+		vr, files := verifyWalkFile(path, fSet, modulePath, baseDir)
+		sum.add(vr)
 		issueFiles = append(issueFiles, files...)
 
 		return nil
@@ -103,11 +150,24 @@ func runVerifyWalk(target string, fSet *token.FileSet, modulePath, baseDir strin
 		return err
 	}
 
-	printVerifySummary(totalAll, missingAll, mismatchAll, issueFiles)
+	finishVerify(sum, issueFiles, []string{smapScope(target, baseDir)}, modulePath, baseDir)
 
 	return nil
 }
 
+// finishVerify 校验 -locs 位置常量表与 -token 映射文件, 并打印包含全部文件的汇总
+func finishVerify(sum verifyResult, issueFiles, scopes []string, modulePath, baseDir string) {
+	locs, locFiles := reportLocTables(modulePath, baseDir)
+	sum.locs = locs
+	issueFiles = append(issueFiles, locFiles...)
+
+	if sum.smap = reportSourceMap(scopes, baseDir); sum.smap > 0 {
+		issueFiles = append(issueFiles, relPath(smapPath(baseDir), baseDir))
+	}
+
+	printVerifySummary(sum, issueFiles)
+}
+
 // handleVerifyDir 判断目录是否应当跳过
 func handleVerifyDir(path string) error {
 	if shouldSkipDir(path) {
@@ -118,74 +178,55 @@ func handleVerifyDir(path string) error {
 }
 
 // verifyWalkFile 对单个文件执行 verify 并返回统计数据及问题文件列表
-func verifyWalkFile(path string, fSet *token.FileSet, modulePath, baseDir string) (int, int, int, []string) {
+func verifyWalkFile(path string, fSet *token.FileSet, modulePath, baseDir string) (verifyResult, []string) {
 	if shouldSkipFile(path) {
-		return 0, 0, 0, nil
+		return verifyResult{}, nil
 	}
 
-	total, missing, mismatch, issues, err := reportVerifyForPath(path, fSet, modulePath, baseDir)
-	if err != nil || total == 0 {
-		return 0, 0, 0, nil
+	vr, err := reportVerifyForPath(path, fSet, modulePath, baseDir)
+	if err != nil {
+		// 无法读取或解析的文件计为失败, -continue-on-error 时同时计入最终的错误汇总
+		rel := relPath(path, baseDir)
+		fmt.Printf("[VERIFY] %s: %v\n", rel, err)
+
+		if *contFlg {
+			failures = append(failures, err)
+		}
+
+		return verifyResult{failed: 1}, []string{rel}
 	}
 
+	recordGenerated(path, false, "", modulePath, baseDir)
+
 	var files []string
 
-	if len(issues) > 0 {
+	if len(vr.issues) > 0 {
 		rel := relPath(path, baseDir)
 		files = append(files, rel)
 	}
 
-	return total, missing, mismatch, files
+	return vr, files
 }
 
-// shouldSkipDir 判断目录路径是否应当跳过(例如 vendor/.git 等), 支持 -exclude
+// shouldSkipDir 判断目录路径是否应当跳过(见 ignore.go): 默认排除、.gitignore/.zap-smapignore 与 -exclude
 func shouldSkipDir(path string) bool {
-	base := filepath.Base(path)
-	if base == "vendor" || base == ".git" || base == "build" || base == "node_modules" {
-		return true
-	}
-
-	pathSl := filepath.ToSlash(path)
-
-	for _, ex := range excludeList {
-		if strings.Contains(ex, "/") {
-			exClean := strings.TrimSuffix(ex, "/")
-			if pathSl == exClean || strings.HasPrefix(pathSl+"/", exClean+"/") {
-				return true
-			}
-		} else if base == ex {
-			return true
-		}
-	}
-
-	return false
+	return currentSkipConfig().skipped(path, true)
 }
 
-// shouldSkipFile 判断文件路径是否应当跳过(非 go 文件、生成文件或特定 internal 路径), 支持 -exclude
+// shouldSkipFile 判断文件路径是否应当跳过: 非 go 文件、位置常量表、-tests 策略、-for 构建依赖图之外,
+// 以及被排除或不满足 -include 的文件
 func shouldSkipFile(path string) bool {
-	// 非 go 文件
-	if !strings.HasSuffix(path, ".go") {
+	// 非 go 文件与 -locs 生成的位置常量表
+	if !strings.HasSuffix(path, ".go") || isLocsFile(path) {
 		return true
 	}
 
-	// 生成文件或特定 internal 目录, 统一使用 '/' 作为内部判断的分隔符
-	pathSl := filepath.ToSlash(path)
-	if strings.HasSuffix(pathSl, "_gen.go") || strings.Contains(pathSl, "/internal/") {
+	// -tests 策略与 -for 构建依赖图之外的文件
+	if skipTestFile(path) || outsideBuildGraph(path) {
 		return true
 	}
 
-	// 检查用户指定的排除列表
-
-	for _, ex := range excludeList {
-		if strings.Contains(ex, "/") {
-			exClean := strings.TrimSuffix(ex, "/")
-			if pathSl == exClean || strings.HasPrefix(pathSl+"/", exClean+"/") {
-				return true
-			}
-		} else if filepath.Base(path) == ex {
-			return true
-		}
-	}
+	c := currentSkipConfig()
 
-	return false
+	return c.skipped(path, false) || !c.included(path)
 }
@@ -90,3 +90,58 @@ func Foo() { zap.L().Info("hello", zap.String("z","3"), zap.String("a","1")) }
 		t.Fatalf("expected order a < fl < z after sort, got: %s", s)
 	}
 }
+
+func TestMain_LevelPolicy_SkipAndStrip(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	td := t.TempDir()
+	writeFile(t, td, "policy.go", `package sample
+
+import "go.uber.org/zap"
+
+func Foo(floor int) {
+	zap.L().Debug("debug", zap.String("fl", "policy.go:6"))
+	zap.L().Info("info", zap.Int("fl", floor))
+	zap.L().Info("info2", zap.String("fl", "policy.go:8"))
+	zap.L().Warn("warn", zap.Int("fl", floor))
+	zap.L().Error("error")
+}
+`)
+
+	*pathFlag = td
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*policyFlg = "Debug=skip,Info=skip,Warn=strip"
+	os.Args = []string{"cmd"}
+
+	_ = captureOutput(func() { main() })
+
+	b, err := os.ReadFile(filepath.Join(td, "policy.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	s := string(b)
+
+	// skip: 清理工具注入的字段, 保留用户自己的同名字段
+	if !strings.Contains(s, `zap.L().Debug("debug")`) {
+		t.Fatalf("expected injected field removed from skipped Debug call, got:\n%s", s)
+	}
+	if !strings.Contains(s, `zap.L().Info("info", zap.Int("fl", floor))`) {
+		t.Fatalf("expected user field kept on skipped Info call, got:\n%s", s)
+	}
+	if !strings.Contains(s, `zap.L().Info("info2")`) {
+		t.Fatalf("expected injected field removed from skipped Info call, got:\n%s", s)
+	}
+
+	// strip: 移除该键的任意字段
+	if !strings.Contains(s, `zap.L().Warn("warn")`) {
+		t.Fatalf("expected field stripped from Warn call, got:\n%s", s)
+	}
+
+	// inject: 其它级别照常注入
+	if !strings.Contains(s, `zap.L().Error("error", zap.String("fl", "policy.go:10"))`) {
+		t.Fatalf("expected Error call injected, got:\n%s", s)
+	}
+}
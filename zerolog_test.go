@@ -0,0 +1,171 @@
+//
+// FilePath    : zap-smap\zerolog_test.go
+// Author      : jiaopengzi
+// Blog        : https://jiaopengzi.com
+// Copyright   : Copyright (c) 2026 by jiaopengzi, All Rights Reserved.
+// Description : zerolog 事件调用链 backend 单测
+//
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runZerologSample 写入 content 并运行工具, 返回处理后的文件内容与输出
+func runZerologSample(t *testing.T, content string) (string, string) {
+	t.Helper()
+
+	td := t.TempDir()
+	writeFile(t, td, "z.go", content)
+
+	*pathFlag = td
+	os.Args = []string{"cmd"}
+
+	out := captureOutput(func() { main() })
+
+	b, err := os.ReadFile(filepath.Join(td, "z.go"))
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	return string(b), out
+}
+
+// TestMain_Zerolog_InjectsIntoEventChains 测试在 Msg/Msgf/Send 结束的事件调用链中插入 .Str, 多行调用链保留换行
+func TestMain_Zerolog_InjectsIntoEventChains(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	*writeFlg = true
+	*fieldFlg = "fl"
+
+	s, _ := runZerologSample(t, `package sample
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func Foo(err error) {
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	log.Info().Msg("plain")
+	log.Error().Err(err).Str("k", "v").Msgf("failed %d", 1)
+	logger.Warn().Send()
+	log.Logger.Debug().
+		Int("n", 1).
+		Msg("multi")
+	log.Info().Str("k", "v")
+}
+`)
+
+	wants := []string{
+		`log.Info().Str("fl", "z.go:13").Msg("plain")`,
+		`log.Error().Str("fl", "z.go:14").Err(err).Str("k", "v").Msgf("failed %d", 1)`,
+		`logger.Warn().Str("fl", "z.go:15").Send()`,
+		"log.Logger.Debug().Str(\"fl\", \"z.go:16\").\n\t\tInt(\"n\", 1).\n\t\tMsg(\"multi\")",
+	}
+	for _, w := range wants {
+		if !strings.Contains(s, w) {
+			t.Fatalf("expected %s, got:\n%s", w, s)
+		}
+	}
+
+	// 没有结束调用的事件不会输出日志, 不处理
+	if !strings.Contains(s, `log.Info().Str("k", "v")`+"\n") {
+		t.Fatalf("expected chain without Msg/Send untouched, got:\n%s", s)
+	}
+}
+
+// TestMain_Zerolog_UpdateDeleteAndPosition 测试已有字段原地更新、-del 删除以及 -position 插入
+func TestMain_Zerolog_UpdateDeleteAndPosition(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	*writeFlg = true
+	*fieldFlg = "fl"
+
+	src := `package sample
+
+import "github.com/rs/zerolog/log"
+
+func Foo() {
+	log.Warn().Str("a", "1").Str("fl", "old.go:1").Msg("warn")
+}
+`
+
+	s, _ := runZerologSample(t, src)
+	if !strings.Contains(s, `log.Warn().Str("a", "1").Str("fl", "z.go:6").Msg("warn")`) {
+		t.Fatalf("expected existing field updated in place, got:\n%s", s)
+	}
+
+	resetGlobals()
+	resetNewFlags()
+
+	*writeFlg = true
+	*delFlg = "fl"
+
+	s, _ = runZerologSample(t, s)
+	if !strings.Contains(s, `log.Warn().Str("a", "1").Msg("warn")`) {
+		t.Fatalf("expected field removed from chain, got:\n%s", s)
+	}
+
+	resetGlobals()
+	resetNewFlags()
+
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*positionFlg = 1
+
+	s, _ = runZerologSample(t, s)
+	if !strings.Contains(s, `log.Warn().Str("a", "1").Str("fl", "z.go:6").Msg("warn")`) {
+		t.Fatalf("expected field inserted after the first chain field, got:\n%s", s)
+	}
+}
+
+// TestMain_Zerolog_VerifyAndPolicy 测试 -verify 校验调用链字段, 以及 -level-policy 对调用链的剥离
+func TestMain_Zerolog_VerifyAndPolicy(t *testing.T) {
+	resetGlobals()
+	resetNewFlags()
+
+	*verifyFlg = true
+	*fieldFlg = "fl"
+
+	src := `package sample
+
+import "github.com/rs/zerolog/log"
+
+func Foo() {
+	log.Info().Str("fl", "z.go:6").Msg("ok")
+	log.Warn().Str("fl", "z.go:1").Msg("stale")
+	log.Error().Msg("missing")
+	log.Debug().Str("fl", "z.go:9").Send()
+}
+`
+
+	_, out := runZerologSample(t, src)
+	if !strings.Contains(out, "total calls: 4") || !strings.Contains(out, "missing: 1") || !strings.Contains(out, "mismatch: 1") {
+		t.Fatalf("expected 4 calls with one missing and one mismatch, got:\n%s", out)
+	}
+	if !strings.Contains(out, "log.Error missing field 'fl'") {
+		t.Fatalf("expected level method name in issue, got:\n%s", out)
+	}
+
+	resetGlobals()
+	resetNewFlags()
+
+	*writeFlg = true
+	*fieldFlg = "fl"
+	*policyFlg = "Debug=skip"
+
+	s, _ := runZerologSample(t, src)
+	if !strings.Contains(s, `log.Debug().Send()`) {
+		t.Fatalf("expected injected field skipped for Debug chain, got:\n%s", s)
+	}
+}